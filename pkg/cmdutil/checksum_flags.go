@@ -0,0 +1,69 @@
+package cmdutil
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// LuhnFlag defines a new string flag for account/card-like identifiers whose digits
+// must pass the Luhn check, catching transcription errors at parse time.
+func LuhnFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string) *pflag.Flag {
+	return ChecksumStringFlag(cmd, p, name, shorthand, defaultValue, validateLuhn, usage)
+}
+
+// ChecksumStringFlag defines a new string flag validated by an arbitrary checksum
+// function, for identifier schemes beyond Luhn.
+func ChecksumStringFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue string, validate func(string) error, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&checksumValue{string: p, validate: validate}, name, shorthand, usage)
+}
+
+type checksumValue struct {
+	string   *string
+	validate func(string) error
+}
+
+func (v *checksumValue) Set(value string) error {
+	if err := v.validate(value); err != nil {
+		return err
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *checksumValue) String() string {
+	return *v.string
+}
+
+func (v *checksumValue) Type() string {
+	return "string"
+}
+
+func validateLuhn(value string) error {
+	if value == "" {
+		return fmt.Errorf("value cannot be empty")
+	}
+	sum := 0
+	double := false
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			return fmt.Errorf("value must contain only digits")
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	if sum%10 != 0 {
+		return fmt.Errorf("invalid check digit")
+	}
+	return nil
+}
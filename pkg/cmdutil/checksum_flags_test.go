@@ -0,0 +1,77 @@
+package cmdutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLuhnFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsError string
+	}{
+		{
+			name:  "valid number",
+			value: "79927398713",
+		},
+		{
+			name:  "valid with leading zero",
+			value: "0000000000000000",
+		},
+		{
+			name:       "bad check digit",
+			value:      "79927398710",
+			wantsError: `invalid argument "79927398710" for "--account" flag: invalid check digit`,
+		},
+		{
+			name:       "non-digit",
+			value:      "7992-7398-713",
+			wantsError: `invalid argument "7992-7398-713" for "--account" flag: value must contain only digits`,
+		},
+		{
+			name:       "empty",
+			value:      "",
+			wantsError: `invalid argument "" for "--account" flag: value cannot be empty`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var account string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			LuhnFlag(cmd, &account, "account", "", "", "the account number")
+			cmd.SetArgs([]string{"--account", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, account)
+		})
+	}
+}
+
+func TestChecksumStringFlag(t *testing.T) {
+	evenLength := func(value string) error {
+		if len(value)%2 != 0 {
+			return fmt.Errorf("length must be even")
+		}
+		return nil
+	}
+
+	var id string
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	ChecksumStringFlag(cmd, &id, "id", "", "", evenLength, "the identifier")
+	cmd.SetArgs([]string{"--id", "abc"})
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+	_, err := cmd.ExecuteC()
+	assert.EqualError(t, err, `invalid argument "abc" for "--id" flag: length must be even`)
+}
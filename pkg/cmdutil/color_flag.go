@@ -0,0 +1,119 @@
+package cmdutil
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// namedCSSColors maps the CSS color keywords commands accept to their RGB values.
+var namedCSSColors = map[string]color.RGBA{
+	"black":   {0x00, 0x00, 0x00, 0xff},
+	"blue":    {0x00, 0x00, 0xff, 0xff},
+	"cyan":    {0x00, 0xff, 0xff, 0xff},
+	"gray":    {0x80, 0x80, 0x80, 0xff},
+	"green":   {0x00, 0x80, 0x00, 0xff},
+	"magenta": {0xff, 0x00, 0xff, 0xff},
+	"orange":  {0xff, 0xa5, 0x00, 0xff},
+	"purple":  {0x80, 0x00, 0x80, 0xff},
+	"red":     {0xff, 0x00, 0x00, 0xff},
+	"white":   {0xff, 0xff, 0xff, 0xff},
+	"yellow":  {0xff, 0xff, 0x00, 0xff},
+}
+
+// ColorFlag defines a new flag that accepts a color as a CSS keyword ("red"), a hex
+// triplet ("#f00" or "#ff0000"), or a functional form ("rgb(255,0,0)" or
+// "rgba(255,0,0,0.5)"), storing the parsed color.RGBA.
+func ColorFlag(cmd *cobra.Command, p *color.RGBA, name, shorthand string, defaultValue color.RGBA, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&colorValue{rgba: p}, name, shorthand, usage)
+}
+
+type colorValue struct {
+	rgba *color.RGBA
+}
+
+func (v *colorValue) Set(value string) error {
+	parsed, err := parseColor(strings.TrimSpace(strings.ToLower(value)))
+	if err != nil {
+		return fmt.Errorf("invalid color %q: %v", value, err)
+	}
+	*v.rgba = parsed
+	return nil
+}
+
+func parseColor(value string) (color.RGBA, error) {
+	if c, ok := namedCSSColors[value]; ok {
+		return c, nil
+	}
+	if strings.HasPrefix(value, "#") {
+		return parseHexColor(value[1:])
+	}
+	if strings.HasPrefix(value, "rgb(") || strings.HasPrefix(value, "rgba(") {
+		return parseRGBFunc(value)
+	}
+	return color.RGBA{}, fmt.Errorf("expected a color name, \"#rgb\", \"#rrggbb\", or \"rgb(r,g,b)\"")
+}
+
+func parseHexColor(hex string) (color.RGBA, error) {
+	switch len(hex) {
+	case 3:
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	case 6:
+	default:
+		return color.RGBA{}, fmt.Errorf("hex colors have 3 or 6 digits")
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid hex digits")
+	}
+	return color.RGBA{R: uint8(n >> 16), G: uint8(n >> 8), B: uint8(n), A: 0xff}, nil
+}
+
+func parseRGBFunc(value string) (color.RGBA, error) {
+	wantAlpha := strings.HasPrefix(value, "rgba(")
+	open := strings.Index(value, "(")
+	if !strings.HasSuffix(value, ")") {
+		return color.RGBA{}, fmt.Errorf("missing closing parenthesis")
+	}
+	args := strings.Split(value[open+1:len(value)-1], ",")
+	wantArgs := 3
+	if wantAlpha {
+		wantArgs = 4
+	}
+	if len(args) != wantArgs {
+		return color.RGBA{}, fmt.Errorf("expected %d comma-separated components", wantArgs)
+	}
+	var channels [3]uint8
+	for i := 0; i < 3; i++ {
+		n, err := strconv.Atoi(strings.TrimSpace(args[i]))
+		if err != nil || n < 0 || n > 255 {
+			return color.RGBA{}, fmt.Errorf("component %q must be an integer between 0 and 255", strings.TrimSpace(args[i]))
+		}
+		channels[i] = uint8(n)
+	}
+	alpha := uint8(0xff)
+	if wantAlpha {
+		a, err := strconv.ParseFloat(strings.TrimSpace(args[3]), 64)
+		if err != nil || a < 0 || a > 1 {
+			return color.RGBA{}, fmt.Errorf("alpha %q must be a number between 0 and 1", strings.TrimSpace(args[3]))
+		}
+		alpha = uint8(a*255 + 0.5)
+	}
+	return color.RGBA{R: channels[0], G: channels[1], B: channels[2], A: alpha}, nil
+}
+
+func (v *colorValue) String() string {
+	if v.rgba == nil {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", v.rgba.R, v.rgba.G, v.rgba.B)
+}
+
+func (v *colorValue) Type() string {
+	return "color"
+}
@@ -0,0 +1,77 @@
+package cmdutil
+
+import (
+	"image/color"
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsColor color.RGBA
+		wantsError string
+	}{
+		{
+			name:       "named color",
+			value:      "red",
+			wantsColor: color.RGBA{0xff, 0x00, 0x00, 0xff},
+		},
+		{
+			name:       "short hex",
+			value:      "#f00",
+			wantsColor: color.RGBA{0xff, 0x00, 0x00, 0xff},
+		},
+		{
+			name:       "long hex",
+			value:      "#1e90ff",
+			wantsColor: color.RGBA{0x1e, 0x90, 0xff, 0xff},
+		},
+		{
+			name:       "rgb function",
+			value:      "rgb(255, 0, 0)",
+			wantsColor: color.RGBA{0xff, 0x00, 0x00, 0xff},
+		},
+		{
+			name:       "rgba function",
+			value:      "rgba(0, 128, 0, 0.5)",
+			wantsColor: color.RGBA{0x00, 0x80, 0x00, 0x80},
+		},
+		{
+			name:       "unknown name",
+			value:      "crimsonish",
+			wantsError: `invalid argument "crimsonish" for "--color" flag: invalid color "crimsonish": expected a color name, "#rgb", "#rrggbb", or "rgb(r,g,b)"`,
+		},
+		{
+			name:       "wrong hex length",
+			value:      "#ff00",
+			wantsError: `invalid argument "#ff00" for "--color" flag: invalid color "#ff00": hex colors have 3 or 6 digits`,
+		},
+		{
+			name:       "component out of range",
+			value:      "rgb(300,0,0)",
+			wantsError: `invalid argument "rgb(300,0,0)" for "--color" flag: invalid color "rgb(300,0,0)": component "300" must be an integer between 0 and 255`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c color.RGBA
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			ColorFlag(cmd, &c, "color", "", color.RGBA{A: 0xff}, "the label color")
+			cmd.SetArgs([]string{"--color", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsColor, c)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package cmdutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// CompletionFunc is the signature cobra expects for dynamic flag completion.
+type CompletionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// HistoryCompletion returns a completion function that suggests recently-used values
+// recorded in the history file at path, most recent first. The file holds one value per
+// line with the most recent entry last; a missing file yields no suggestions.
+func HistoryCompletion(path string, limit int) CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		seen := map[string]bool{}
+		var results []string
+		for i := len(lines) - 1; i >= 0; i-- {
+			entry := strings.TrimSpace(lines[i])
+			if entry == "" || seen[entry] || !strings.HasPrefix(entry, toComplete) {
+				continue
+			}
+			seen[entry] = true
+			results = append(results, entry)
+			if limit > 0 && len(results) == limit {
+				break
+			}
+		}
+		return results, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// CombineCompletions returns a completion function that merges the suggestions from
+// each source in order, dropping duplicates. Useful for layering recently-used values
+// on top of a static or dynamic option list.
+func CombineCompletions(sources ...CompletionFunc) CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		directive := cobra.ShellCompDirectiveNoFileComp
+		seen := map[string]bool{}
+		var results []string
+		for _, source := range sources {
+			values, d := source(cmd, args, toComplete)
+			directive |= d
+			for _, value := range values {
+				if !seen[value] {
+					seen[value] = true
+					results = append(results, value)
+				}
+			}
+		}
+		return results, directive
+	}
+}
+
+// AppendToHistory records value as the most recent entry in the history file at path,
+// creating the file and any parent directories as needed.
+func AppendToHistory(path, value string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, value)
+	return err
+}
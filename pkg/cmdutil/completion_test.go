@@ -0,0 +1,46 @@
+package cmdutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryCompletion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	for _, entry := range []string{"alpha", "beta", "alpha", "gamma"} {
+		require.NoError(t, AppendToHistory(path, entry))
+	}
+
+	complete := HistoryCompletion(path, 2)
+
+	results, directive := complete(&cobra.Command{}, nil, "")
+	assert.Equal(t, []string{"gamma", "alpha"}, results)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	results, _ = complete(&cobra.Command{}, nil, "al")
+	assert.Equal(t, []string{"alpha"}, results)
+}
+
+func TestHistoryCompletionMissingFile(t *testing.T) {
+	complete := HistoryCompletion(filepath.Join(t.TempDir(), "nope"), 5)
+	results, directive := complete(&cobra.Command{}, nil, "")
+	assert.Empty(t, results)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
+
+func TestCombineCompletions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	require.NoError(t, AppendToHistory(path, "beta"))
+
+	static := func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"alpha", "beta"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	complete := CombineCompletions(HistoryCompletion(path, 0), static)
+
+	results, _ := complete(&cobra.Command{}, nil, "")
+	assert.Equal(t, []string{"beta", "alpha"}, results)
+}
@@ -0,0 +1,44 @@
+package cmdutil
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// DecodedFlag defines a new flag whose raw value is parsed by decode, storing the typed
+// result in *p and surfacing decode failures as flag errors. It is the general "parse
+// into a typed value" primitive underlying the simple single-value constructors such as
+// RegexpCompileFlag, LatLngFlag, and ThresholdFlag.
+func DecodedFlag[T any](cmd *cobra.Command, p *T, name, shorthand, usage string, decode func(string) (T, error)) *pflag.Flag {
+	return cmd.Flags().VarPF(&decodedValue[T]{value: p, decode: decode}, name, shorthand, usage)
+}
+
+type decodedValue[T any] struct {
+	value *T
+	raw   string
+	// typeName overrides the generic "value" shown in usage docs when a constructor
+	// builds on decodedValue directly.
+	typeName string
+	decode   func(string) (T, error)
+}
+
+func (v *decodedValue[T]) Set(value string) error {
+	decoded, err := v.decode(value)
+	if err != nil {
+		return err
+	}
+	*v.value = decoded
+	v.raw = value
+	return nil
+}
+
+func (v *decodedValue[T]) String() string {
+	return v.raw
+}
+
+func (v *decodedValue[T]) Type() string {
+	if v.typeName != "" {
+		return v.typeName
+	}
+	return "value"
+}
@@ -0,0 +1,47 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodedFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsHost  string
+		wantsError string
+	}{
+		{
+			name:      "decodes into typed value",
+			value:     "https://example.com/path",
+			wantsHost: "example.com",
+		},
+		{
+			name:       "decode error surfaces",
+			value:      "::notaurl",
+			wantsError: `invalid argument "::notaurl" for "--endpoint" flag: parse "::notaurl": missing protocol scheme`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var endpoint *url.URL
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			DecodedFlag(cmd, &endpoint, "endpoint", "", "the endpoint URL", url.Parse)
+			cmd.SetArgs([]string{"--endpoint", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsHost, endpoint.Host)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package cmdutil
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// RetryPolicy is a retry configuration parsed from a single flag value such as
+// "attempts=5,backoff=exponential,max=30s".
+type RetryPolicy struct {
+	Attempts int
+	Backoff  string
+	Max      time.Duration
+}
+
+var retryBackoffs = []string{"constant", "linear", "exponential"}
+
+// RetryPolicyFlag defines a new flag bundling a retry policy into one validated value.
+// Unspecified fields keep the defaults already present in *p.
+func RetryPolicyFlag(cmd *cobra.Command, p *RetryPolicy, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&retryPolicyValue{policy: p}, name, shorthand, usage)
+}
+
+type retryPolicyValue struct {
+	policy *RetryPolicy
+}
+
+func (v *retryPolicyValue) Set(value string) error {
+	policy := *v.policy
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) < 2 {
+			return fmt.Errorf("invalid entry %q: expected \"key=value\"", entry)
+		}
+		switch key, val := parts[0], parts[1]; key {
+		case "attempts":
+			attempts, err := strconv.Atoi(val)
+			if err != nil || attempts <= 0 {
+				return fmt.Errorf("invalid attempts %q: must be a positive integer", val)
+			}
+			policy.Attempts = attempts
+		case "backoff":
+			if !isEnumOption(val, retryBackoffs) {
+				return fmt.Errorf("invalid backoff %q: valid values are %s", val, formatValuesForUsageDocs(retryBackoffs))
+			}
+			policy.Backoff = val
+		case "max":
+			max, err := time.ParseDuration(val)
+			if err != nil {
+				return fmt.Errorf("invalid max %q: %v", val, err)
+			}
+			policy.Max = max
+		default:
+			return fmt.Errorf("unknown key %q: valid keys are {attempts|backoff|max}", key)
+		}
+	}
+	*v.policy = policy
+	return nil
+}
+
+func (v *retryPolicyValue) String() string {
+	if v.policy == nil {
+		return ""
+	}
+	return fmt.Sprintf("attempts=%d,backoff=%s,max=%s", v.policy.Attempts, v.policy.Backoff, v.policy.Max)
+}
+
+func (v *retryPolicyValue) Type() string {
+	return "policy"
+}
+
+// BoundedDurationFlag defines a new duration flag whose value must fall within
+// [min, max]; out-of-range values are an error. See ClampedDurationFlag for the
+// forgiving variant.
+func BoundedDurationFlag(cmd *cobra.Command, p *time.Duration, name, shorthand string, defaultValue, min, max time.Duration, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&boundedDurationValue{duration: p, min: min, max: max}, name, shorthand, usage)
+}
+
+type boundedDurationValue struct {
+	duration *time.Duration
+	min, max time.Duration
+}
+
+func (v *boundedDurationValue) Set(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if d < v.min || d > v.max {
+		return fmt.Errorf("must be between %s and %s", v.min, v.max)
+	}
+	*v.duration = d
+	return nil
+}
+
+func (v *boundedDurationValue) String() string {
+	return v.duration.String()
+}
+
+func (v *boundedDurationValue) Type() string {
+	return "duration"
+}
+
+// ClampedDurationFlag defines a new duration flag that clamps out-of-range values to
+// the nearest of [min, max] with a warning printed to stderr, instead of erroring
+// like BoundedDurationFlag. Useful where a best-effort value beats failing outright.
+func ClampedDurationFlag(cmd *cobra.Command, p *time.Duration, name, shorthand string, defaultValue, min, max time.Duration, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&clampedDurationValue{duration: p, name: name, min: min, max: max}, name, shorthand, usage)
+}
+
+type clampedDurationValue struct {
+	duration *time.Duration
+	name     string
+	min, max time.Duration
+}
+
+func (v *clampedDurationValue) Set(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	clamped := d
+	if clamped < v.min {
+		clamped = v.min
+	} else if clamped > v.max {
+		clamped = v.max
+	}
+	if clamped != d {
+		fmt.Fprintf(os.Stderr, "warning: %s clamped to %s\n", v.name, clamped)
+	}
+	*v.duration = clamped
+	return nil
+}
+
+func (v *clampedDurationValue) String() string {
+	return v.duration.String()
+}
+
+func (v *clampedDurationValue) Type() string {
+	return "duration"
+}
+
+// DurationFlagWithDefaultUnit defines a new duration flag that additionally accepts a
+// bare number, which is interpreted in defaultUnit. This lets users type "--timeout 30"
+// for thirty seconds while still accepting the usual "30s"/"5m" forms.
+func DurationFlagWithDefaultUnit(cmd *cobra.Command, p *time.Duration, name, shorthand string, defaultValue, defaultUnit time.Duration, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&defaultUnitDurationValue{duration: p, unit: defaultUnit}, name, shorthand, usage)
+}
+
+type defaultUnitDurationValue struct {
+	duration *time.Duration
+	unit     time.Duration
+}
+
+func (v *defaultUnitDurationValue) Set(value string) error {
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		*v.duration = time.Duration(n * float64(v.unit))
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*v.duration = d
+	return nil
+}
+
+func (v *defaultUnitDurationValue) String() string {
+	return v.duration.String()
+}
+
+func (v *defaultUnitDurationValue) Type() string {
+	return "duration"
+}
@@ -0,0 +1,172 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyFlag(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantsPolicy RetryPolicy
+		wantsError  string
+	}{
+		{
+			name:        "full policy",
+			value:       "attempts=5,backoff=exponential,max=30s",
+			wantsPolicy: RetryPolicy{Attempts: 5, Backoff: "exponential", Max: 30 * time.Second},
+		},
+		{
+			name:        "partial policy keeps defaults",
+			value:       "attempts=2",
+			wantsPolicy: RetryPolicy{Attempts: 2, Backoff: "constant", Max: time.Minute},
+		},
+		{
+			name:       "invalid attempts",
+			value:      "attempts=0",
+			wantsError: `invalid argument "attempts=0" for "--retry" flag: invalid attempts "0": must be a positive integer`,
+		},
+		{
+			name:       "invalid backoff",
+			value:      "backoff=fibonacci",
+			wantsError: `invalid argument "backoff=fibonacci" for "--retry" flag: invalid backoff "fibonacci": valid values are {constant|linear|exponential}`,
+		},
+		{
+			name:       "invalid max",
+			value:      "max=fast",
+			wantsError: `invalid argument "max=fast" for "--retry" flag: invalid max "fast": time: invalid duration "fast"`,
+		},
+		{
+			name:       "unknown key",
+			value:      "jitter=1",
+			wantsError: `invalid argument "jitter=1" for "--retry" flag: unknown key "jitter": valid keys are {attempts|backoff|max}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := RetryPolicy{Attempts: 1, Backoff: "constant", Max: time.Minute}
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			RetryPolicyFlag(cmd, &policy, "retry", "", "the retry policy")
+			cmd.SetArgs([]string{"--retry", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsPolicy, policy)
+		})
+	}
+}
+
+func TestDurationFlagWithDefaultUnit(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantsDuration time.Duration
+		wantsError    string
+	}{
+		{
+			name:          "no flag uses default",
+			args:          []string{},
+			wantsDuration: time.Minute,
+		},
+		{
+			name:          "unitless value uses default unit",
+			args:          []string{"--timeout", "30"},
+			wantsDuration: 30 * time.Second,
+		},
+		{
+			name:          "fractional unitless value",
+			args:          []string{"--timeout", "1.5"},
+			wantsDuration: 1500 * time.Millisecond,
+		},
+		{
+			name:          "value with unit",
+			args:          []string{"--timeout", "5m"},
+			wantsDuration: 5 * time.Minute,
+		},
+		{
+			name:       "malformed value",
+			args:       []string{"--timeout", "soon"},
+			wantsError: `invalid argument "soon" for "--timeout" flag: time: invalid duration "soon"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var timeout time.Duration
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			DurationFlagWithDefaultUnit(cmd, &timeout, "timeout", "", time.Minute, time.Second, "the timeout")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsDuration, timeout)
+		})
+	}
+}
+
+func TestBoundedDurationFlag(t *testing.T) {
+	var interval time.Duration
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	BoundedDurationFlag(cmd, &interval, "poll-interval", "", 5*time.Second, time.Second, time.Minute, "how often to poll")
+	cmd.SetArgs([]string{"--poll-interval", "500ms"})
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+	_, err := cmd.ExecuteC()
+	assert.EqualError(t, err, `invalid argument "500ms" for "--poll-interval" flag: must be between 1s and 1m0s`)
+}
+
+func TestClampedDurationFlag(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantsInterval time.Duration
+	}{
+		{
+			name:          "default",
+			args:          []string{},
+			wantsInterval: 5 * time.Second,
+		},
+		{
+			name:          "in range",
+			args:          []string{"--poll-interval", "10s"},
+			wantsInterval: 10 * time.Second,
+		},
+		{
+			name:          "clamped up to min",
+			args:          []string{"--poll-interval", "100ms"},
+			wantsInterval: time.Second,
+		},
+		{
+			name:          "clamped down to max",
+			args:          []string{"--poll-interval", "5m"},
+			wantsInterval: time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var interval time.Duration
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			ClampedDurationFlag(cmd, &interval, "poll-interval", "", 5*time.Second, time.Second, time.Minute, "how often to poll")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsInterval, interval)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package cmdutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// EncodedTokenFlag defines a new flag that decodes its value using the named encoding
+// ("base32", "base64", or "base64url") and stores the raw bytes. When expectedLen is
+// nonzero the decoded length must match, e.g. 20 bytes for a TOTP secret.
+func EncodedTokenFlag(cmd *cobra.Command, p *[]byte, name, shorthand string, encoding string, expectedLen int, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&encodedTokenValue{bytes: p, encoding: encoding, expectedLen: expectedLen}, name, shorthand, usage)
+}
+
+type encodedTokenValue struct {
+	bytes       *[]byte
+	raw         string
+	encoding    string
+	expectedLen int
+}
+
+func (v *encodedTokenValue) Set(value string) error {
+	var decoded []byte
+	var err error
+	switch v.encoding {
+	case "base32":
+		decoded, err = base32.StdEncoding.DecodeString(strings.ToUpper(value))
+	case "base64":
+		decoded, err = base64.StdEncoding.DecodeString(value)
+	case "base64url":
+		decoded, err = base64.URLEncoding.DecodeString(value)
+	default:
+		return fmt.Errorf("unsupported encoding %q", v.encoding)
+	}
+	if err != nil {
+		return fmt.Errorf("not valid %s: %v", v.encoding, err)
+	}
+	if v.expectedLen > 0 && len(decoded) != v.expectedLen {
+		return fmt.Errorf("decoded to %d bytes, expected %d", len(decoded), v.expectedLen)
+	}
+	*v.bytes = decoded
+	v.raw = value
+	return nil
+}
+
+func (v *encodedTokenValue) String() string {
+	return v.raw
+}
+
+func (v *encodedTokenValue) Type() string {
+	return "token"
+}
+
+// base58BitcoinAlphabet is the Bitcoin base58 alphabet, which omits 0, O, I, and l to
+// avoid transcription ambiguity.
+const base58BitcoinAlphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Base58Opts customizes the behavior of Base58Flag.
+type Base58Opts struct {
+	// Alphabet is the 58-character digit alphabet; defaults to the Bitcoin alphabet.
+	Alphabet string
+	// Check validates and strips a trailing Base58Check checksum (the first 4 bytes of
+	// a double SHA-256 of the payload), storing only the payload.
+	Check bool
+}
+
+// Base58Flag defines a new flag that decodes a base58 value, e.g. a crypto address,
+// and stores the raw bytes. At most one Base58Opts may be passed.
+func Base58Flag(cmd *cobra.Command, p *[]byte, name, shorthand, usage string, opts ...Base58Opts) *pflag.Flag {
+	val := &base58Value{bytes: p}
+	if len(opts) > 0 {
+		val.opts = opts[0]
+	}
+	if val.opts.Alphabet == "" {
+		val.opts.Alphabet = base58BitcoinAlphabet
+	}
+	return cmd.Flags().VarPF(val, name, shorthand, usage)
+}
+
+type base58Value struct {
+	bytes *[]byte
+	raw   string
+	opts  Base58Opts
+}
+
+func (v *base58Value) Set(value string) error {
+	decoded, err := decodeBase58(value, v.opts.Alphabet)
+	if err != nil {
+		return err
+	}
+	if v.opts.Check {
+		if len(decoded) < 5 {
+			return fmt.Errorf("too short for a base58check value")
+		}
+		payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+		first := sha256.Sum256(payload)
+		second := sha256.Sum256(first[:])
+		if !bytes.Equal(checksum, second[:4]) {
+			return fmt.Errorf("invalid base58check checksum")
+		}
+		decoded = payload
+	}
+	*v.bytes = decoded
+	v.raw = value
+	return nil
+}
+
+func (v *base58Value) String() string {
+	return v.raw
+}
+
+func (v *base58Value) Type() string {
+	return "base58"
+}
+
+// decodeBase58 decodes a base58 value using the given alphabet, where leading
+// occurrences of the zero digit encode leading zero bytes.
+func decodeBase58(value, alphabet string) ([]byte, error) {
+	leading := 0
+	for leading < len(value) && value[leading] == alphabet[0] {
+		leading++
+	}
+	// little-endian accumulator; each digit multiplies through by 58
+	var raw []byte
+	for _, c := range value {
+		carry := strings.IndexRune(alphabet, c)
+		if carry < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		for i := 0; i < len(raw); i++ {
+			carry += int(raw[i]) * 58
+			raw[i] = byte(carry & 0xff)
+			carry >>= 8
+		}
+		for carry > 0 {
+			raw = append(raw, byte(carry&0xff))
+			carry >>= 8
+		}
+	}
+	decoded := make([]byte, leading, leading+len(raw))
+	for i := len(raw) - 1; i >= 0; i-- {
+		decoded = append(decoded, raw[i])
+	}
+	return decoded, nil
+}
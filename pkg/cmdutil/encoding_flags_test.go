@@ -0,0 +1,129 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodedTokenFlag(t *testing.T) {
+	tests := []struct {
+		name        string
+		encoding    string
+		expectedLen int
+		value       string
+		wantsBytes  []byte
+		wantsError  string
+	}{
+		{
+			name:        "base32 TOTP secret",
+			encoding:    "base32",
+			expectedLen: 20,
+			value:       "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+			wantsBytes:  []byte("12345678901234567890"),
+		},
+		{
+			name:       "base64",
+			encoding:   "base64",
+			value:      "aGVsbG8=",
+			wantsBytes: []byte("hello"),
+		},
+		{
+			name:       "base64url",
+			encoding:   "base64url",
+			value:      "aGVsbG8_IQ==",
+			wantsBytes: []byte("hello?!"),
+		},
+		{
+			name:       "bad encoding",
+			encoding:   "base64",
+			value:      "not base64!!",
+			wantsError: `invalid argument "not base64!!" for "--token" flag: not valid base64: illegal base64 data at input byte 3`,
+		},
+		{
+			name:        "wrong decoded length",
+			encoding:    "base32",
+			expectedLen: 20,
+			value:       "GEZDGNBV",
+			wantsError:  `invalid argument "GEZDGNBV" for "--token" flag: decoded to 5 bytes, expected 20`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var token []byte
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			EncodedTokenFlag(cmd, &token, "token", "", tt.encoding, tt.expectedLen, "the token")
+			cmd.SetArgs([]string{"--token", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsBytes, token)
+		})
+	}
+}
+
+func TestBase58Flag(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		opts      []Base58Opts
+		wantBytes []byte
+		wantErr   string
+	}{
+		{
+			name:      "plain base58",
+			args:      []string{"--address", "StV1DL6CwTryKyV"},
+			wantBytes: []byte("hello world"),
+		},
+		{
+			name:      "leading zero digits",
+			args:      []string{"--address", "11StV1DL6CwTryKyV"},
+			wantBytes: append([]byte{0, 0}, []byte("hello world")...),
+		},
+		{
+			name:    "invalid character",
+			args:    []string{"--address", "St0V"},
+			wantErr: `invalid argument "St0V" for "--address" flag: invalid base58 character '0'`,
+		},
+		{
+			name: "valid base58check",
+			args: []string{"--address", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+			opts: []Base58Opts{{Check: true}},
+			wantBytes: []byte{
+				0x00, 0x62, 0xe9, 0x07, 0xb1, 0x5c, 0xbf, 0x27, 0xd5, 0x42, 0x53,
+				0x99, 0xeb, 0xf6, 0xf0, 0xfb, 0x50, 0xeb, 0xb8, 0x8f, 0x18,
+			},
+		},
+		{
+			name:    "corrupted base58check",
+			args:    []string{"--address", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb"},
+			opts:    []Base58Opts{{Check: true}},
+			wantErr: `invalid argument "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb" for "--address" flag: invalid base58check checksum`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var address []byte
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			Base58Flag(cmd, &address, "address", "", "address to decode", tt.opts...)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBytes, address)
+		})
+	}
+}
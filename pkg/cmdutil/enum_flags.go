@@ -0,0 +1,1171 @@
+package cmdutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// EnumWildcard is the value accepted by enum flags configured with AllowWildcard,
+// conventionally meaning "all options".
+const EnumWildcard = "*"
+
+// EnumOpts customizes the behavior of enum flags beyond plain membership validation.
+// Each field is implemented by the constructors named in its comment; passing an
+// option to a constructor that does not implement it panics at registration time
+// rather than silently disabling a check the caller asked for.
+type EnumOpts struct {
+	// Implies lists values that are automatically selected whenever the keyed value is.
+	// Honored by BitmaskEnumFlag.
+	Implies map[string][]string
+	// Conflicts lists values that may not be combined with the keyed value. Honored by
+	// BitmaskEnumFlag.
+	Conflicts map[string][]string
+	// AllowWildcard accepts "*" as a value, bypassing the option check. The wildcard is
+	// stored literally; use WildcardSelected to test for it. Honored by StringEnumFlag.
+	AllowWildcard bool
+	// EscapedSeparators allows a backslash to escape the comma separator inside an
+	// element, e.g. `a\,b,c` parses as ["a,b", "c"]. A doubled backslash produces a
+	// literal backslash. Honored by StringSliceEnumFlag, OrderedEnumFlag,
+	// WeightedEnumFlag, EnumCountMapFlag, OrderedEnumCountFlag, and
+	// OrderedTypedPairsFlag.
+	EscapedSeparators bool
+	// Categories assigns options to named categories; at most one value per category
+	// may be selected in a multi-value flag. Honored by StringSliceEnumFlag.
+	Categories map[string]string
+	// Requires lists prerequisite values that must also be selected whenever the keyed
+	// value is, e.g. "deploy" requiring "test". Unlike Implies, missing prerequisites
+	// are an error rather than being added automatically. Honored by OrderedEnumFlag.
+	Requires map[string][]string
+	// PromptWhenUnset prompts for a value during PreRun when the flag was not given and
+	// the session is interactive; in non-interactive mode an unset flag is an error.
+	// Honored by StringEnumFlag.
+	PromptWhenUnset bool
+	// Prompter performs the interactive selection for PromptWhenUnset, re-prompting
+	// until a valid option is chosen or it returns an error. Defaults to a survey
+	// select; tests inject a fake. Honored by StringEnumFlag.
+	Prompter func(message string, options []string) (string, error)
+	// Interactive reports whether prompting is possible. Defaults to checking that
+	// stdin is a terminal. Honored by StringEnumFlag.
+	Interactive func() bool
+	// LenientUnknown stores values not listed in options after printing a warning to
+	// stderr, instead of rejecting them. This lets the CLI forward options added by a
+	// newer server without a release. Honored by StringSliceEnumFlag.
+	LenientUnknown bool
+	// CompanionFlags maps options to flags that must also be set whenever the option
+	// is selected, e.g. "email" requiring --email-address. Checked from PreRunE.
+	// Honored by StringSliceEnumFlag.
+	CompanionFlags map[string][]string
+	// Available maps options to entitlement predicates evaluated during PreRunE;
+	// selecting an option whose predicate returns false is an error, and such options
+	// are hidden from completion. Options without a predicate are always available.
+	// Honored by StringEnumFlag and StringSliceEnumFlag.
+	Available map[string]func() bool
+	// ConfirmValues lists dangerous values that may only be selected when the boolean
+	// flag named by ConfirmFlag was passed, e.g. "delete" requiring --confirm.
+	// Honored by StringSliceEnumFlag.
+	ConfirmValues []string
+	// ConfirmFlag names the confirmation flag checked for ConfirmValues. Defaults to
+	// "confirm". Honored by StringSliceEnumFlag.
+	ConfirmFlag string
+	// RequiredGroups lists sets of option values; the final selection must include at
+	// least one member of each group, e.g. requiring both a read-ish and a write-ish
+	// capability. Checked from PreRunE once all uses of the flag are parsed. Honored
+	// by StringSliceEnumFlag.
+	RequiredGroups [][]string
+	// ValidWindows maps options to the time window in which they may be selected,
+	// e.g. a promo code valid for one month. Selecting an option outside its window
+	// is an error, checked from PreRunE, and such options are hidden from completion.
+	// Options without a window are always selectable. Honored by StringEnumFlag and
+	// StringSliceEnumFlag.
+	ValidWindows map[string]EnumWindow
+	// CountOccurrences keeps duplicate values instead of collapsing them, for flags
+	// where repetition expresses weight, e.g. "--vote a,a,b". Use EnumSelectionCounts
+	// to read the multiplicity of each value. Honored by StringSliceEnumFlag.
+	CountOccurrences bool
+	// IDs maps friendly option names to the internal identifiers stored in the
+	// selection, e.g. "cache" to "plugin-cache-v2". Validation, completion, usage
+	// docs, and error messages all speak in the friendly names; only the stored
+	// values are translated. Options without a mapping are stored as given. Honored
+	// by StringSliceEnumFlag.
+	IDs map[string]string
+	// AliasFile names a file mapping canonical options to aliases, one mapping per
+	// line in the form "canonical=alias1,alias2" ("#" starts a comment). Aliases are
+	// resolved to their canonical value at Set time, so the vocabulary can evolve
+	// without a release. A missing file simply means no aliases. Completion offers
+	// canonical names only. Honored by StringSliceEnumFlag.
+	AliasFile string
+}
+
+// assertSupportedEnumOpts panics when opts carries a non-zero field outside supported.
+// Each EnumOpts field is honored by a subset of the enum constructors, and silently
+// ignoring a validation option the caller asked for would disable a safety check;
+// failing fast at registration time surfaces the mistake in development instead.
+func assertSupportedEnumOpts(constructor string, opts EnumOpts, supported ...string) {
+	v := reflect.ValueOf(opts)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsZero() {
+			continue
+		}
+		name := v.Type().Field(i).Name
+		ok := false
+		for _, s := range supported {
+			if s == name {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			panic(fmt.Sprintf("cmdutil: %s does not support EnumOpts.%s", constructor, name))
+		}
+	}
+}
+
+// availableEnumOptions filters options to those whose availability predicate (if any)
+// returns true.
+func availableEnumOptions(options []string, available map[string]func() bool) []string {
+	if len(available) == 0 {
+		return options
+	}
+	var usable []string
+	for _, opt := range options {
+		if predicate, ok := available[opt]; ok && !predicate() {
+			continue
+		}
+		usable = append(usable, opt)
+	}
+	return usable
+}
+
+// EnumWindow bounds when an enum option may be selected. A zero From or Until leaves
+// that side unbounded; Until is exclusive.
+type EnumWindow struct {
+	From  time.Time
+	Until time.Time
+}
+
+func (w EnumWindow) contains(t time.Time) bool {
+	if !w.From.IsZero() && t.Before(w.From) {
+		return false
+	}
+	if !w.Until.IsZero() && !t.Before(w.Until) {
+		return false
+	}
+	return true
+}
+
+// windowedEnumOptions filters options to those whose time window (if any) contains the
+// current time.
+func windowedEnumOptions(options []string, windows map[string]EnumWindow) []string {
+	if len(windows) == 0 {
+		return options
+	}
+	now := time.Now()
+	var open []string
+	for _, opt := range options {
+		if w, ok := windows[opt]; ok && !w.contains(now) {
+			continue
+		}
+		open = append(open, opt)
+	}
+	return open
+}
+
+// checkEnumWindows validates a selection against option time windows, returning an
+// error naming the first selected option outside its window.
+func checkEnumWindows(selection []string, windows map[string]EnumWindow) error {
+	now := time.Now()
+	for _, element := range selection {
+		if w, ok := windows[element]; ok && !w.contains(now) {
+			return FlagErrorf("option %q is not currently available", element)
+		}
+	}
+	return nil
+}
+
+// checkEnumAvailable validates a selection against availability predicates, returning
+// an error naming the first selected-but-unavailable value.
+func checkEnumAvailable(name string, selection []string, available map[string]func() bool) error {
+	for _, element := range selection {
+		if predicate, ok := available[element]; ok && !predicate() {
+			return FlagErrorf("%s %q is not available on your plan", name, element)
+		}
+	}
+	return nil
+}
+
+// loadEnumAliases reads an AliasFile into an alias→canonical map. A missing file
+// yields an empty map.
+func loadEnumAliases(path string) map[string]string {
+	aliases := map[string]string{}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return aliases
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		parts := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(parts) < 2 {
+			continue
+		}
+		canonical := strings.TrimSpace(parts[0])
+		for _, alias := range strings.Split(parts[1], ",") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				aliases[strings.ToLower(alias)] = canonical
+			}
+		}
+	}
+	return aliases
+}
+
+// checkEnumRequires validates a selection against the prerequisite graph in requires,
+// returning an error naming the first unsatisfied dependency.
+func checkEnumRequires(selection []string, requires map[string][]string) error {
+	for _, element := range selection {
+		for _, prerequisite := range requires[element] {
+			if !isEnumOption(prerequisite, selection) {
+				return fmt.Errorf("%s requires %s which was not selected", element, prerequisite)
+			}
+		}
+	}
+	return nil
+}
+
+// WildcardSelected reports whether an enum flag value is the "*" wildcard.
+func WildcardSelected(value string) bool {
+	return value == EnumWildcard
+}
+
+// BitmaskEnumFlag defines a new flag that accepts a comma-separated list of enum values
+// and stores the selection as a bitmask, where bit i corresponds to options[i]. Repeated
+// uses of the flag accumulate into the mask. At most 64 options are supported.
+func BitmaskEnumFlag(cmd *cobra.Command, p *uint64, name, shorthand string, options []string, opts EnumOpts, usage string) *pflag.Flag {
+	*p = 0
+	assertSupportedEnumOpts("BitmaskEnumFlag", opts, "Implies", "Conflicts")
+	val := &bitmaskEnumValue{mask: p, options: options, opts: opts}
+	f := cmd.Flags().VarPF(val, name, shorthand, fmt.Sprintf("%s: %s", usage, formatValuesForUsageDocs(options)))
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return options, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+// BitmaskNames converts a mask produced by BitmaskEnumFlag back to the names of its set
+// bits, in option order. options must be the same list the flag was registered with.
+func BitmaskNames(mask uint64, options []string) []string {
+	var names []string
+	for i, opt := range options {
+		if mask&(1<<uint(i)) != 0 {
+			names = append(names, opt)
+		}
+	}
+	return names
+}
+
+// WeightedEnumFlag defines a new flag that accepts comma-separated "option=weight"
+// pairs, e.g. "a=70,b=30", where each option must be a member of options and the
+// weights are percentages that must sum to 100. At most one EnumOpts may be passed.
+func WeightedEnumFlag(cmd *cobra.Command, p *map[string]float64, name, shorthand string, options []string, usage string, opts ...EnumOpts) *pflag.Flag {
+	val := &weightedEnumValue{weights: p, options: options}
+	if len(opts) > 0 {
+		assertSupportedEnumOpts("WeightedEnumFlag", opts[0], "EscapedSeparators")
+		val.opts = opts[0]
+	}
+	f := cmd.Flags().VarPF(val, name, shorthand, fmt.Sprintf("%s: %s", usage, formatValuesForUsageDocs(options)))
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return options, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+type weightedEnumValue struct {
+	weights *map[string]float64
+	options []string
+	opts    EnumOpts
+}
+
+func (v *weightedEnumValue) Set(value string) error {
+	weights := map[string]float64{}
+	total := 0.0
+	for _, entry := range splitFlagList(value, v.opts) {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) < 2 {
+			return fmt.Errorf("invalid entry %q: expected \"option=weight\"", entry)
+		}
+		if !isEnumOption(parts[0], v.options) {
+			return fmt.Errorf("invalid value %q: valid values are %s", parts[0], formatValuesForUsageDocs(v.options))
+		}
+		weight, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || weight < 0 {
+			return fmt.Errorf("invalid weight %q for %q", parts[1], parts[0])
+		}
+		weights[parts[0]] = weight
+		total += weight
+	}
+	if math.Abs(total-100) > 1e-9 {
+		return fmt.Errorf("weights must sum to 100 (got %g)", total)
+	}
+	*v.weights = weights
+	return nil
+}
+
+func (v *weightedEnumValue) String() string {
+	if v.weights == nil || *v.weights == nil {
+		return ""
+	}
+	entries := make([]string, 0, len(*v.weights))
+	for _, opt := range v.options {
+		if weight, ok := (*v.weights)[opt]; ok {
+			entries = append(entries, fmt.Sprintf("%s=%g", opt, weight))
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+func (v *weightedEnumValue) Type() string {
+	return "strings"
+}
+
+func isEnumOption(value string, options []string) bool {
+	for _, opt := range options {
+		if strings.EqualFold(opt, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// StringSliceEnumFlag defines a new multi-value flag whose comma-separated elements
+// must all be listed in options. Repeated uses accumulate, and duplicate elements are
+// collapsed. At most one EnumOpts may be passed to customize validation.
+func StringSliceEnumFlag(cmd *cobra.Command, p *[]string, name, shorthand string, defaults, options []string, usage string, opts ...EnumOpts) *pflag.Flag {
+	*p = defaults
+	val := &enumMultiValue{value: p, options: options}
+	if len(opts) > 0 {
+		assertSupportedEnumOpts("StringSliceEnumFlag", opts[0],
+			"EscapedSeparators", "Categories", "LenientUnknown", "CompanionFlags",
+			"Available", "ConfirmValues", "ConfirmFlag", "RequiredGroups",
+			"ValidWindows", "CountOccurrences", "IDs", "AliasFile")
+		val.opts = opts[0]
+	}
+	// the PreRun checks below are keyed by friendly option names, so they consult
+	// friendlyNames() rather than *p, whose values may be IDs-translated
+	if len(val.opts.Available) > 0 {
+		registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+			return checkEnumAvailable(name, val.friendlyNames(), val.opts.Available)
+		})
+	}
+	if len(val.opts.ValidWindows) > 0 {
+		registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+			return checkEnumWindows(val.friendlyNames(), val.opts.ValidWindows)
+		})
+	}
+	if len(val.opts.CompanionFlags) > 0 {
+		registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+			for _, element := range val.friendlyNames() {
+				for _, companion := range val.opts.CompanionFlags[element] {
+					if f := cmd.Flags().Lookup(companion); f == nil || !f.Changed {
+						return FlagErrorf("%s=%s requires --%s", name, element, companion)
+					}
+				}
+			}
+			return nil
+		})
+	}
+	if len(val.opts.ConfirmValues) > 0 {
+		confirmFlag := val.opts.ConfirmFlag
+		if confirmFlag == "" {
+			confirmFlag = "confirm"
+		}
+		registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+			confirmed, _ := cmd.Flags().GetBool(confirmFlag)
+			if confirmed {
+				return nil
+			}
+			for _, dangerous := range val.opts.ConfirmValues {
+				if isEnumOption(dangerous, val.friendlyNames()) {
+					return FlagErrorf("value %q for `--%s` requires --%s", dangerous, name, confirmFlag)
+				}
+			}
+			return nil
+		})
+	}
+	if len(val.opts.RequiredGroups) > 0 {
+		registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+			for _, group := range val.opts.RequiredGroups {
+				satisfied := false
+				for _, member := range group {
+					if isEnumOption(member, val.friendlyNames()) {
+						satisfied = true
+						break
+					}
+				}
+				if !satisfied {
+					return FlagErrorf("must select at least one of {%s}", strings.Join(group, ", "))
+				}
+			}
+			return nil
+		})
+	}
+	f := cmd.Flags().VarPF(val, name, shorthand, fmt.Sprintf("%s: %s", usage, formatValuesForUsageDocs(options)))
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		usable := availableEnumOptions(options, val.opts.Available)
+		return windowedEnumOptions(usable, val.opts.ValidWindows), cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+type enumMultiValue struct {
+	value   *[]string
+	options []string
+	opts    EnumOpts
+	aliases map[string]string
+	// friendly mirrors the selection in user-facing vocabulary when IDs translation
+	// is active, so PreRun checks keyed by friendly names still see what the user
+	// typed rather than the stored internal identifiers.
+	friendly []string
+	set      bool
+}
+
+// friendlyNames returns the selection in the user-facing vocabulary. Without IDs
+// translation (or before any explicit use) the stored values already are the friendly
+// names.
+func (v *enumMultiValue) friendlyNames() []string {
+	if len(v.opts.IDs) == 0 || !v.set {
+		return *v.value
+	}
+	return v.friendly
+}
+
+func (v *enumMultiValue) Set(value string) error {
+	elements := splitFlagList(value, v.opts)
+	selection := *v.value
+	friendly := v.friendly
+	if !v.set {
+		// the first explicit use replaces the defaults
+		selection = nil
+		friendly = nil
+	}
+	if v.opts.AliasFile != "" && v.aliases == nil {
+		v.aliases = loadEnumAliases(v.opts.AliasFile)
+	}
+	for _, element := range elements {
+		if canonical, ok := v.aliases[strings.ToLower(element)]; ok {
+			element = canonical
+		}
+		if !isEnumOption(element, v.options) {
+			if !v.opts.LenientUnknown {
+				return fmt.Errorf("invalid value %q: valid values are %s", element, formatValuesForUsageDocs(v.options))
+			}
+			fmt.Fprintf(os.Stderr, "warning: unrecognized value %q passed through without validation\n", element)
+		}
+		friendlyElement := element
+		if len(v.opts.IDs) > 0 {
+			for name, id := range v.opts.IDs {
+				if strings.EqualFold(name, element) {
+					element = id
+					break
+				}
+			}
+		}
+		if v.opts.CountOccurrences || !isEnumOption(element, selection) {
+			selection = append(selection, element)
+			friendly = append(friendly, friendlyElement)
+		}
+	}
+	if len(v.opts.Categories) > 0 {
+		selectedPerCategory := map[string]string{}
+		for _, element := range selection {
+			category, ok := v.opts.Categories[element]
+			if !ok {
+				continue
+			}
+			if previous, taken := selectedPerCategory[category]; taken {
+				return fmt.Errorf("cannot select both %q and %q (category: %s)", previous, element, category)
+			}
+			selectedPerCategory[category] = element
+		}
+	}
+	*v.value = selection
+	v.friendly = friendly
+	v.set = true
+	return nil
+}
+
+func (v *enumMultiValue) GetSlice() []string {
+	return *v.value
+}
+
+func (v *enumMultiValue) Append(value string) error {
+	return v.Set(value)
+}
+
+func (v *enumMultiValue) Replace(values []string) error {
+	v.set = false
+	return v.Set(strings.Join(values, ","))
+}
+
+func (v *enumMultiValue) String() string {
+	return strings.Join(*v.value, ",")
+}
+
+func (v *enumMultiValue) Type() string {
+	return "strings"
+}
+
+// CanonicalEnumSelection returns the selection of a StringSliceEnumFlag in a
+// deterministic canonical form: each value mapped to its option spelling, lowercased,
+// deduped, sorted, and joined by commas. Equivalent invocations yield identical
+// strings regardless of input order or casing, making the result safe to use in cache
+// keys. Returns "" if f is not a multi-select enum flag.
+func CanonicalEnumSelection(f *pflag.Flag) string {
+	v, ok := f.Value.(*enumMultiValue)
+	if !ok {
+		return ""
+	}
+	seen := map[string]bool{}
+	var canonical []string
+	for _, element := range *v.value {
+		for _, option := range v.options {
+			if strings.EqualFold(element, option) {
+				element = option
+				break
+			}
+		}
+		element = strings.ToLower(element)
+		if !seen[element] {
+			seen[element] = true
+			canonical = append(canonical, element)
+		}
+	}
+	sort.Strings(canonical)
+	return strings.Join(canonical, ",")
+}
+
+// EnumSelectionCounts returns how many times each value was given to a multi-select
+// enum flag registered with CountOccurrences. Returns nil if f is not a multi-select
+// enum flag.
+func EnumSelectionCounts(f *pflag.Flag) map[string]int {
+	v, ok := f.Value.(*enumMultiValue)
+	if !ok {
+		return nil
+	}
+	counts := map[string]int{}
+	for _, element := range *v.value {
+		counts[element]++
+	}
+	return counts
+}
+
+// splitFlagList splits a comma-separated flag value, honoring backslash escapes when
+// opts.EscapedSeparators is set.
+func splitFlagList(value string, opts EnumOpts) []string {
+	if !opts.EscapedSeparators {
+		return strings.Split(value, ",")
+	}
+	return splitEscaped(value, ',')
+}
+
+// splitEscaped splits s on sep, treating a backslash as an escape for sep or for
+// another backslash. A trailing backslash is kept literally.
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	var current strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) && (s[i+1] == sep || s[i+1] == '\\') {
+			current.WriteByte(s[i+1])
+			i++
+			continue
+		}
+		if c == sep {
+			parts = append(parts, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteByte(c)
+	}
+	return append(parts, current.String())
+}
+
+// SignedEnumOpts customizes the behavior of SignedEnumFlag.
+type SignedEnumOpts struct {
+	// AllowRemovingAbsent ignores "-" operations naming values not currently in the
+	// selection instead of erroring.
+	AllowRemovingAbsent bool
+	// MinSelected is the number of values that must remain selected once every use of
+	// the flag has applied; zero means the default of 1, so negations can never empty
+	// the set into a silently no-op command. Checked from PreRunE.
+	MinSelected int
+}
+
+// checkMinSelected validates the final size of a narrowed selection against min, where
+// a min of zero means the default of 1.
+func checkMinSelected(selection []string, min int) error {
+	if min == 0 {
+		min = 1
+	}
+	if len(selection) >= min {
+		return nil
+	}
+	if len(selection) == 0 {
+		return FlagErrorf("no targets selected after applying exclusions")
+	}
+	return FlagErrorf("only %d targets selected after applying exclusions, need at least %d", len(selection), min)
+}
+
+// SignedEnumFlag defines a new multi-value flag that edits a default selection using
+// explicitly signed values, e.g. "+read,+write,-delete", where "+" adds to and "-"
+// removes from the set. Operations apply in order and each referenced value must be
+// listed in options.
+func SignedEnumFlag(cmd *cobra.Command, p *[]string, name, shorthand string, defaults, options []string, usage string, opts ...SignedEnumOpts) *pflag.Flag {
+	// copy so removals never mutate the caller's defaults slice
+	*p = append([]string(nil), defaults...)
+	val := &signedEnumValue{value: p, options: options}
+	if len(opts) > 0 {
+		val.opts = opts[0]
+	}
+	f := cmd.Flags().VarPF(val, name, shorthand, fmt.Sprintf("%s: %s", usage, formatValuesForUsageDocs(options)))
+	registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+		if !f.Changed {
+			return nil
+		}
+		return checkMinSelected(*p, val.opts.MinSelected)
+	})
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return options, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+type signedEnumValue struct {
+	value   *[]string
+	options []string
+	opts    SignedEnumOpts
+}
+
+func (v *signedEnumValue) Set(value string) error {
+	selection := *v.value
+	for _, entry := range strings.Split(value, ",") {
+		if entry == "" || (entry[0] != '+' && entry[0] != '-') {
+			return fmt.Errorf("invalid entry %q: entries must begin with \"+\" or \"-\"", entry)
+		}
+		element := entry[1:]
+		if !isEnumOption(element, v.options) {
+			return fmt.Errorf("invalid value %q: valid values are %s", element, formatValuesForUsageDocs(v.options))
+		}
+		if entry[0] == '+' {
+			if !isEnumOption(element, selection) {
+				selection = append(selection, element)
+			}
+			continue
+		}
+		removed := false
+		for i, existing := range selection {
+			if strings.EqualFold(existing, element) {
+				selection = append(selection[:i], selection[i+1:]...)
+				removed = true
+				break
+			}
+		}
+		if !removed && !v.opts.AllowRemovingAbsent {
+			return fmt.Errorf("cannot remove %q: not in the current selection", element)
+		}
+	}
+	*v.value = selection
+	return nil
+}
+
+func (v *signedEnumValue) GetSlice() []string {
+	return *v.value
+}
+
+func (v *signedEnumValue) Append(value string) error {
+	return v.Set(value)
+}
+
+func (v *signedEnumValue) Replace(values []string) error {
+	*v.value = nil
+	return v.Set(strings.Join(values, ","))
+}
+
+func (v *signedEnumValue) String() string {
+	return strings.Join(*v.value, ",")
+}
+
+func (v *signedEnumValue) Type() string {
+	return "strings"
+}
+
+// OrderedEnumFlag defines a new multi-value flag like StringSliceEnumFlag for cases
+// where the order of the selection is semantically significant, e.g. a fallback order.
+// Elements are validated against options, their order is preserved exactly, and
+// duplicates are an error rather than being collapsed.
+func OrderedEnumFlag(cmd *cobra.Command, p *[]string, name, shorthand string, options []string, usage string, opts ...EnumOpts) *pflag.Flag {
+	val := &orderedEnumValue{value: p, options: options}
+	if len(opts) > 0 {
+		assertSupportedEnumOpts("OrderedEnumFlag", opts[0], "Requires", "EscapedSeparators")
+		val.opts = opts[0]
+	}
+	if val.opts.Requires != nil {
+		// checked from PreRunE so prerequisites given in a later use of the flag count
+		registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+			if err := checkEnumRequires(*p, val.opts.Requires); err != nil {
+				return FlagErrorf("%v", err)
+			}
+			return nil
+		})
+	}
+	f := cmd.Flags().VarPF(val, name, shorthand, fmt.Sprintf("%s: %s", usage, formatValuesForUsageDocs(options)))
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return options, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+type orderedEnumValue struct {
+	value   *[]string
+	options []string
+	opts    EnumOpts
+}
+
+func (v *orderedEnumValue) Set(value string) error {
+	selection := *v.value
+	for _, element := range splitFlagList(value, v.opts) {
+		if !isEnumOption(element, v.options) {
+			return fmt.Errorf("invalid value %q: valid values are %s", element, formatValuesForUsageDocs(v.options))
+		}
+		if isEnumOption(element, selection) {
+			return fmt.Errorf("duplicate value %q", element)
+		}
+		selection = append(selection, element)
+	}
+	*v.value = selection
+	return nil
+}
+
+func (v *orderedEnumValue) GetSlice() []string {
+	return *v.value
+}
+
+func (v *orderedEnumValue) Append(value string) error {
+	return v.Set(value)
+}
+
+func (v *orderedEnumValue) Replace(values []string) error {
+	*v.value = nil
+	return v.Set(strings.Join(values, ","))
+}
+
+func (v *orderedEnumValue) String() string {
+	return strings.Join(*v.value, ",")
+}
+
+func (v *orderedEnumValue) Type() string {
+	return "strings"
+}
+
+// ExcludeEnum holds the result of an ExcludeEnumFlag: the values the user excluded
+// and, via Remaining, the complement the command should act on.
+type ExcludeEnum struct {
+	excluded []string
+	options  []string
+}
+
+// Excluded returns the values the user excluded, in the order given.
+func (e *ExcludeEnum) Excluded() []string {
+	return e.excluded
+}
+
+// Remaining returns the options that were not excluded, in option order.
+func (e *ExcludeEnum) Remaining() []string {
+	var remaining []string
+	for _, opt := range e.options {
+		if !isEnumOption(opt, e.excluded) {
+			remaining = append(remaining, opt)
+		}
+	}
+	return remaining
+}
+
+// ExcludeEnumOpts customizes the behavior of ExcludeEnumFlag.
+type ExcludeEnumOpts struct {
+	// MinSelected is the number of options that must remain after the exclusions
+	// apply; zero means the default of 1. Checked from PreRunE.
+	MinSelected int
+}
+
+// ExcludeEnumFlag defines a new multi-value flag expressing an inverse selection, e.g.
+// `--exclude-checks lint,vet` meaning "run everything else". Excluded values are
+// validated against options and the complement is available from p.Remaining(). At
+// most one ExcludeEnumOpts may be passed.
+func ExcludeEnumFlag(cmd *cobra.Command, p *ExcludeEnum, name, shorthand string, options []string, usage string, opts ...ExcludeEnumOpts) *pflag.Flag {
+	p.options = options
+	var minSelected int
+	if len(opts) > 0 {
+		minSelected = opts[0].MinSelected
+	}
+	f := cmd.Flags().VarPF(&excludeEnumValue{result: p}, name, shorthand, fmt.Sprintf("%s: %s", usage, formatValuesForUsageDocs(options)))
+	registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+		if !f.Changed {
+			return nil
+		}
+		return checkMinSelected(p.Remaining(), minSelected)
+	})
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return options, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+type excludeEnumValue struct {
+	result *ExcludeEnum
+}
+
+func (v *excludeEnumValue) Set(value string) error {
+	excluded := v.result.excluded
+	for _, element := range strings.Split(value, ",") {
+		if !isEnumOption(element, v.result.options) {
+			return fmt.Errorf("invalid value %q: valid values are %s", element, formatValuesForUsageDocs(v.result.options))
+		}
+		if !isEnumOption(element, excluded) {
+			excluded = append(excluded, element)
+		}
+	}
+	v.result.excluded = excluded
+	return nil
+}
+
+func (v *excludeEnumValue) String() string {
+	if v.result == nil {
+		return ""
+	}
+	return strings.Join(v.result.excluded, ",")
+}
+
+func (v *excludeEnumValue) Type() string {
+	return "strings"
+}
+
+// ModalEnumFlag defines a new string flag whose valid values depend on another flag,
+// e.g. `--value` allowing {x, y} when `--mode a` and {p, q} when `--mode b`.
+// modeOptions maps each mode to its allowed values. Validation runs in PreRunE once
+// both flags are parsed; completion offers the options for the mode already given on
+// the command line, falling back to the union of all modes when it is not.
+func ModalEnumFlag(cmd *cobra.Command, p *string, name, shorthand, modeFlag string, modeOptions map[string][]string, usage string) *pflag.Flag {
+	cmd.Flags().StringVarP(p, name, shorthand, "", usage)
+	f := cmd.Flags().Lookup(name)
+	registerFlagCheck(cmd, func(c *cobra.Command) error {
+		if !f.Changed {
+			return nil
+		}
+		mode := ""
+		if mf := c.Flags().Lookup(modeFlag); mf != nil {
+			mode = mf.Value.String()
+		}
+		options, ok := modeOptions[mode]
+		if !ok {
+			return FlagErrorf("--%s is not valid when `--%s` is %q", name, modeFlag, mode)
+		}
+		if !isEnumOption(*p, options) {
+			return FlagErrorf("invalid value %q for `--%s` when `--%s` is %q: valid values are %s",
+				*p, name, modeFlag, mode, formatValuesForUsageDocs(options))
+		}
+		return nil
+	})
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if mf := cmd.Flags().Lookup(modeFlag); mf != nil && mf.Changed {
+			return modeOptions[mf.Value.String()], cobra.ShellCompDirectiveNoFileComp
+		}
+		seen := map[string]bool{}
+		var union []string
+		for _, options := range modeOptions {
+			for _, opt := range options {
+				if !seen[opt] {
+					seen[opt] = true
+					union = append(union, opt)
+				}
+			}
+		}
+		sort.Strings(union)
+		return union, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+// EnvEnumFlag defines a new string flag whose valid values are read from the named
+// environment variable as a comma-separated list, letting deployment-specific option
+// sets drive validation without recompilation. The variable is consulted lazily at
+// validation and completion time; when it is unset, any value is accepted with a
+// warning printed to stderr.
+func EnvEnumFlag(cmd *cobra.Command, p *string, name, shorthand, envVar, defaultValue, usage string) *pflag.Flag {
+	*p = defaultValue
+	val := &envEnumValue{string: p, envVar: envVar}
+	f := cmd.Flags().VarPF(val, name, shorthand, fmt.Sprintf("%s (valid values from $%s)", usage, envVar))
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return val.envOptions(), cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+type envEnumValue struct {
+	string *string
+	envVar string
+}
+
+func (v *envEnumValue) envOptions() []string {
+	raw, ok := os.LookupEnv(v.envVar)
+	if !ok {
+		return nil
+	}
+	var options []string
+	for _, opt := range strings.Split(raw, ",") {
+		if opt = strings.TrimSpace(opt); opt != "" {
+			options = append(options, opt)
+		}
+	}
+	return options
+}
+
+func (v *envEnumValue) Set(value string) error {
+	options := v.envOptions()
+	if options == nil {
+		fmt.Fprintf(os.Stderr, "warning: $%s is not set; accepting %q without validation\n", v.envVar, value)
+		*v.string = value
+		return nil
+	}
+	if !isEnumOption(value, options) {
+		return fmt.Errorf("valid values are %s", formatValuesForUsageDocs(options))
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *envEnumValue) String() string {
+	return *v.string
+}
+
+func (v *envEnumValue) Type() string {
+	return "string"
+}
+
+// EnumCountMapFlag defines a new flag that accepts comma-separated "option=count"
+// pairs, e.g. "web=3,db=1", where each key must be a member of options and each count
+// a positive integer. Repeated uses merge into the same map. At most one EnumOpts may
+// be passed.
+func EnumCountMapFlag(cmd *cobra.Command, p *map[string]int, name, shorthand string, options []string, usage string, opts ...EnumOpts) *pflag.Flag {
+	val := &enumCountMapValue{counts: p, options: options}
+	if len(opts) > 0 {
+		assertSupportedEnumOpts("EnumCountMapFlag", opts[0], "EscapedSeparators")
+		val.opts = opts[0]
+	}
+	f := cmd.Flags().VarPF(val, name, shorthand, fmt.Sprintf("%s: %s", usage, formatValuesForUsageDocs(options)))
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return options, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+type enumCountMapValue struct {
+	counts  *map[string]int
+	options []string
+	opts    EnumOpts
+}
+
+func (v *enumCountMapValue) Set(value string) error {
+	counts := map[string]int{}
+	for _, entry := range splitFlagList(value, v.opts) {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) < 2 {
+			return fmt.Errorf("invalid entry %q: expected \"option=count\"", entry)
+		}
+		if !isEnumOption(parts[0], v.options) {
+			return fmt.Errorf("invalid value %q: valid values are %s", parts[0], formatValuesForUsageDocs(v.options))
+		}
+		count, err := strconv.Atoi(parts[1])
+		if err != nil || count <= 0 {
+			return fmt.Errorf("invalid count %q for %q: must be a positive integer", parts[1], parts[0])
+		}
+		counts[parts[0]] = count
+	}
+	if *v.counts == nil {
+		*v.counts = map[string]int{}
+	}
+	for key, count := range counts {
+		(*v.counts)[key] = count
+	}
+	return nil
+}
+
+func (v *enumCountMapValue) String() string {
+	if v.counts == nil || *v.counts == nil {
+		return ""
+	}
+	entries := make([]string, 0, len(*v.counts))
+	for _, opt := range v.options {
+		if count, ok := (*v.counts)[opt]; ok {
+			entries = append(entries, fmt.Sprintf("%s=%d", opt, count))
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+func (v *enumCountMapValue) Type() string {
+	return "strings"
+}
+
+// EnumCount pairs an enum value with a positive quantity, e.g. an instance type and
+// how many of it to provision.
+type EnumCount struct {
+	Value string
+	Count int
+}
+
+// OrderedEnumCountFlag defines a new flag that accepts comma-separated "option:count"
+// pairs, e.g. "t2.micro:2,t2.large:1", where each option must be a member of options
+// and each count a positive integer. Unlike EnumCountMapFlag the pairs accumulate into
+// an ordered slice, preserving the order given and allowing an option to repeat. At
+// most one EnumOpts may be passed.
+func OrderedEnumCountFlag(cmd *cobra.Command, p *[]EnumCount, name, shorthand string, options []string, usage string, opts ...EnumOpts) *pflag.Flag {
+	val := &orderedEnumCountValue{pairs: p, options: options}
+	if len(opts) > 0 {
+		assertSupportedEnumOpts("OrderedEnumCountFlag", opts[0], "EscapedSeparators")
+		val.opts = opts[0]
+	}
+	f := cmd.Flags().VarPF(val, name, shorthand, fmt.Sprintf("%s: %s", usage, formatValuesForUsageDocs(options)))
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return options, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+type orderedEnumCountValue struct {
+	pairs   *[]EnumCount
+	options []string
+	opts    EnumOpts
+}
+
+func (v *orderedEnumCountValue) Set(value string) error {
+	pairs := *v.pairs
+	for _, entry := range splitFlagList(value, v.opts) {
+		colon := strings.LastIndex(entry, ":")
+		if colon < 0 {
+			return fmt.Errorf("invalid entry %q: expected \"option:count\"", entry)
+		}
+		option, raw := entry[:colon], entry[colon+1:]
+		if !isEnumOption(option, v.options) {
+			return fmt.Errorf("invalid value %q: valid values are %s", option, formatValuesForUsageDocs(v.options))
+		}
+		count, err := strconv.Atoi(raw)
+		if err != nil || count <= 0 {
+			return fmt.Errorf("invalid count %q for %q: must be a positive integer", raw, option)
+		}
+		pairs = append(pairs, EnumCount{Value: option, Count: count})
+	}
+	*v.pairs = pairs
+	return nil
+}
+
+func (v *orderedEnumCountValue) String() string {
+	if v.pairs == nil || *v.pairs == nil {
+		return ""
+	}
+	entries := make([]string, len(*v.pairs))
+	for i, pair := range *v.pairs {
+		entries[i] = fmt.Sprintf("%s:%d", pair.Value, pair.Count)
+	}
+	return strings.Join(entries, ",")
+}
+
+func (v *orderedEnumCountValue) Type() string {
+	return "strings"
+}
+
+type bitmaskEnumValue struct {
+	mask    *uint64
+	options []string
+	opts    EnumOpts
+}
+
+func (v *bitmaskEnumValue) Set(value string) error {
+	mask := *v.mask
+	for _, entry := range strings.Split(value, ",") {
+		bit, err := v.bitFor(entry)
+		if err != nil {
+			return err
+		}
+		mask |= bit
+	}
+	// apply implications until the mask stops growing, so chains like a→b→c resolve
+	for {
+		expanded := mask
+		for from, to := range v.opts.Implies {
+			fromBit, err := v.bitFor(from)
+			if err != nil {
+				return err
+			}
+			if expanded&fromBit == 0 {
+				continue
+			}
+			for _, implied := range to {
+				bit, err := v.bitFor(implied)
+				if err != nil {
+					return err
+				}
+				expanded |= bit
+			}
+		}
+		if expanded == mask {
+			break
+		}
+		mask = expanded
+	}
+	for from, against := range v.opts.Conflicts {
+		fromBit, err := v.bitFor(from)
+		if err != nil {
+			return err
+		}
+		if mask&fromBit == 0 {
+			continue
+		}
+		for _, other := range against {
+			bit, err := v.bitFor(other)
+			if err != nil {
+				return err
+			}
+			if mask&bit != 0 {
+				return fmt.Errorf("%q conflicts with %q", from, other)
+			}
+		}
+	}
+	*v.mask = mask
+	return nil
+}
+
+func (v *bitmaskEnumValue) bitFor(name string) (uint64, error) {
+	for i, opt := range v.options {
+		if strings.EqualFold(opt, name) {
+			return 1 << uint(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid value %q: valid values are %s", name, formatValuesForUsageDocs(v.options))
+}
+
+func (v *bitmaskEnumValue) String() string {
+	return strings.Join(BitmaskNames(*v.mask, v.options), ",")
+}
+
+func (v *bitmaskEnumValue) Type() string {
+	return "strings"
+}
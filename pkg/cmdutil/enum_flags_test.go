@@ -0,0 +1,1367 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringEnumFlagAllowWildcard(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       EnumOpts
+		value      string
+		wantsValue string
+		wantsError string
+	}{
+		{
+			name:       "wildcard allowed",
+			opts:       EnumOpts{AllowWildcard: true},
+			value:      "*",
+			wantsValue: "*",
+		},
+		{
+			name:       "member still validated",
+			opts:       EnumOpts{AllowWildcard: true},
+			value:      "staging",
+			wantsValue: "staging",
+		},
+		{
+			name:       "non-member still rejected",
+			opts:       EnumOpts{AllowWildcard: true},
+			value:      "qa",
+			wantsError: `invalid argument "qa" for "--namespace" flag: valid values are {prod|staging}`,
+		},
+		{
+			name:       "wildcard rejected by default",
+			value:      "*",
+			wantsError: `invalid argument "*" for "--namespace" flag: valid values are {prod|staging}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ns string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringEnumFlag(cmd, &ns, "namespace", "", "", []string{"prod", "staging"}, "the namespace", tt.opts)
+			cmd.SetArgs([]string{"--namespace", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, ns)
+			assert.Equal(t, tt.wantsValue == "*", WildcardSelected(ns))
+		})
+	}
+}
+
+func TestWeightedEnumFlag(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        string
+		wantsWeights map[string]float64
+		wantsError   string
+	}{
+		{
+			name:         "weights summing to 100",
+			value:        "a=70,b=30",
+			wantsWeights: map[string]float64{"a": 70, "b": 30},
+		},
+		{
+			name:       "weights not summing to 100",
+			value:      "a=70,b=20",
+			wantsError: `invalid argument "a=70,b=20" for "--route-to" flag: weights must sum to 100 (got 90)`,
+		},
+		{
+			name:       "unknown option",
+			value:      "a=70,z=30",
+			wantsError: `invalid argument "a=70,z=30" for "--route-to" flag: invalid value "z": valid values are {a|b|c}`,
+		},
+		{
+			name:       "missing weight",
+			value:      "a",
+			wantsError: `invalid argument "a" for "--route-to" flag: invalid entry "a": expected "option=weight"`,
+		},
+		{
+			name:       "negative weight",
+			value:      "a=-30,b=130",
+			wantsError: `invalid argument "a=-30,b=130" for "--route-to" flag: invalid weight "-30" for "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var weights map[string]float64
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			WeightedEnumFlag(cmd, &weights, "route-to", "", []string{"a", "b", "c"}, "traffic split")
+			cmd.SetArgs([]string{"--route-to", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsWeights, weights)
+		})
+	}
+}
+
+func TestSignedEnumFlag(t *testing.T) {
+	options := []string{"read", "write", "delete"}
+	defaults := []string{"read", "delete"}
+	tests := []struct {
+		name        string
+		opts        []SignedEnumOpts
+		args        []string
+		wantsValues []string
+		wantsError  string
+	}{
+		{
+			name:        "no flag keeps defaults",
+			args:        []string{},
+			wantsValues: []string{"read", "delete"},
+		},
+		{
+			name:        "add and remove",
+			args:        []string{"--scope", "+write,-delete"},
+			wantsValues: []string{"read", "write"},
+		},
+		{
+			name:       "missing sign",
+			args:       []string{"--scope", "write"},
+			wantsError: `invalid argument "write" for "--scope" flag: invalid entry "write": entries must begin with "+" or "-"`,
+		},
+		{
+			name:       "unknown value",
+			args:       []string{"--scope", "+admin"},
+			wantsError: `invalid argument "+admin" for "--scope" flag: invalid value "admin": valid values are {read|write|delete}`,
+		},
+		{
+			name:       "removing absent value",
+			args:       []string{"--scope", "-write"},
+			wantsError: `invalid argument "-write" for "--scope" flag: cannot remove "write": not in the current selection`,
+		},
+		{
+			name:        "removing absent value allowed",
+			opts:        []SignedEnumOpts{{AllowRemovingAbsent: true}},
+			args:        []string{"--scope", "-write"},
+			wantsValues: []string{"read", "delete"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var scopes []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			SignedEnumFlag(cmd, &scopes, "scope", "", defaults, options, "the scopes", tt.opts...)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValues, scopes)
+		})
+	}
+}
+
+func TestOrderedEnumFlag(t *testing.T) {
+	options := []string{"primary", "secondary", "tertiary"}
+	tests := []struct {
+		name        string
+		args        []string
+		wantsValues []string
+		wantsError  string
+	}{
+		{
+			name:        "preserves order",
+			args:        []string{"--fallback-order", "tertiary,primary"},
+			wantsValues: []string{"tertiary", "primary"},
+		},
+		{
+			name:        "repeated uses append",
+			args:        []string{"--fallback-order", "secondary", "--fallback-order", "primary"},
+			wantsValues: []string{"secondary", "primary"},
+		},
+		{
+			name:       "duplicate errors",
+			args:       []string{"--fallback-order", "primary,secondary,primary"},
+			wantsError: `invalid argument "primary,secondary,primary" for "--fallback-order" flag: duplicate value "primary"`,
+		},
+		{
+			name:       "unknown value",
+			args:       []string{"--fallback-order", "primary,backup"},
+			wantsError: `invalid argument "primary,backup" for "--fallback-order" flag: invalid value "backup": valid values are {primary|secondary|tertiary}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var order []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			OrderedEnumFlag(cmd, &order, "fallback-order", "", options, "the fallback order")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValues, order)
+		})
+	}
+}
+
+func TestEnvEnumFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		envSet     bool
+		value      string
+		wantsValue string
+		wantsError string
+	}{
+		{
+			name:       "value in env set",
+			env:        "us-east,eu-west",
+			envSet:     true,
+			value:      "eu-west",
+			wantsValue: "eu-west",
+		},
+		{
+			name:       "value not in env set",
+			env:        "us-east,eu-west",
+			envSet:     true,
+			value:      "apac",
+			wantsError: `invalid argument "apac" for "--region" flag: valid values are {us-east|eu-west}`,
+		},
+		{
+			name:       "env unset accepts anything",
+			value:      "anything",
+			wantsValue: "anything",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envSet {
+				t.Setenv("APP_REGIONS", tt.env)
+			} else {
+				os.Unsetenv("APP_REGIONS")
+			}
+			var region string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			EnvEnumFlag(cmd, &region, "region", "", "APP_REGIONS", "", "the region")
+			cmd.SetArgs([]string{"--region", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, region)
+		})
+	}
+}
+
+func TestStringSliceEnumFlag(t *testing.T) {
+	options := []string{"a,b", "c", "d"}
+	tests := []struct {
+		name        string
+		opts        EnumOpts
+		args        []string
+		wantsValues []string
+		wantsError  string
+	}{
+		{
+			name:        "no flag keeps defaults",
+			args:        []string{},
+			wantsValues: []string{"c"},
+		},
+		{
+			name:        "explicit use replaces defaults and dedups",
+			args:        []string{"--tags", "d,c", "--tags", "c"},
+			wantsValues: []string{"d", "c"},
+		},
+		{
+			name:        "escaped separator",
+			opts:        EnumOpts{EscapedSeparators: true},
+			args:        []string{"--tags", `a\,b,c`},
+			wantsValues: []string{"a,b", "c"},
+		},
+		{
+			name:       "escaping disabled by default",
+			args:       []string{"--tags", `a\,b`},
+			wantsError: `invalid argument "a\\,b" for "--tags" flag: invalid value "a\\": valid values are {a,b|c|d}`,
+		},
+		{
+			name:       "unknown value",
+			args:       []string{"--tags", "c,x"},
+			wantsError: `invalid argument "c,x" for "--tags" flag: invalid value "x": valid values are {a,b|c|d}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tags []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringSliceEnumFlag(cmd, &tags, "tags", "", []string{"c"}, options, "the tags", tt.opts)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValues, tags)
+		})
+	}
+}
+
+func TestStringSliceEnumFlagCategories(t *testing.T) {
+	options := []string{"red", "blue", "small", "large"}
+	categories := map[string]string{"red": "color", "blue": "color", "small": "size", "large": "size"}
+	tests := []struct {
+		name        string
+		args        []string
+		wantsValues []string
+		wantsError  string
+	}{
+		{
+			name:        "one per category",
+			args:        []string{"--tags", "red,small"},
+			wantsValues: []string{"red", "small"},
+		},
+		{
+			name:       "two in one category",
+			args:       []string{"--tags", "red,blue"},
+			wantsError: `invalid argument "red,blue" for "--tags" flag: cannot select both "red" and "blue" (category: color)`,
+		},
+		{
+			name:       "category collision across uses",
+			args:       []string{"--tags", "red", "--tags", "blue"},
+			wantsError: `invalid argument "blue" for "--tags" flag: cannot select both "red" and "blue" (category: color)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tags []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringSliceEnumFlag(cmd, &tags, "tags", "", nil, options, "the tags", EnumOpts{Categories: categories})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValues, tags)
+		})
+	}
+}
+
+func TestSplitEscaped(t *testing.T) {
+	tests := []struct {
+		input string
+		wants []string
+	}{
+		{input: "a,b,c", wants: []string{"a", "b", "c"}},
+		{input: `a\,b,c`, wants: []string{"a,b", "c"}},
+		{input: `a\\,b`, wants: []string{`a\`, "b"}},
+		{input: `a\\\,b`, wants: []string{`a\,b`}},
+		{input: `a,b\`, wants: []string{"a", `b\`}},
+		{input: "", wants: []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.wants, splitEscaped(tt.input, ','))
+		})
+	}
+}
+
+func TestEnumCountMapFlag(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		opts        []EnumOpts
+		options     []string
+		wantsCounts map[string]int
+		wantsError  string
+	}{
+		{
+			name:        "valid pairs",
+			args:        []string{"--replicas", "web=3,db=1"},
+			wantsCounts: map[string]int{"web": 3, "db": 1},
+		},
+		{
+			name:        "repeated uses merge",
+			args:        []string{"--replicas", "web=3", "--replicas", "db=2,web=1"},
+			wantsCounts: map[string]int{"web": 1, "db": 2},
+		},
+		{
+			name:       "unknown key",
+			args:       []string{"--replicas", "cache=2"},
+			wantsError: `invalid argument "cache=2" for "--replicas" flag: invalid value "cache": valid values are {web|db}`,
+		},
+		{
+			name:       "non-positive count",
+			args:       []string{"--replicas", "web=0"},
+			wantsError: `invalid argument "web=0" for "--replicas" flag: invalid count "0" for "web": must be a positive integer`,
+		},
+		{
+			name:       "missing count",
+			args:       []string{"--replicas", "web"},
+			wantsError: `invalid argument "web" for "--replicas" flag: invalid entry "web": expected "option=count"`,
+		},
+		{
+			name:        "escaped comma inside an option",
+			args:        []string{"--replicas", `web\,east=3,db=1`},
+			opts:        []EnumOpts{{EscapedSeparators: true}},
+			options:     []string{"web,east", "db"},
+			wantsCounts: map[string]int{"web,east": 3, "db": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := tt.options
+			if options == nil {
+				options = []string{"web", "db"}
+			}
+			var replicas map[string]int
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			EnumCountMapFlag(cmd, &replicas, "replicas", "", options, "replica counts", tt.opts...)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsCounts, replicas)
+		})
+	}
+}
+
+func TestBitmaskEnumFlag(t *testing.T) {
+	options := []string{"read", "write", "admin"}
+	tests := []struct {
+		name       string
+		opts       EnumOpts
+		args       []string
+		wantsMask  uint64
+		wantsNames []string
+		wantsError string
+	}{
+		{
+			name:       "single value",
+			args:       []string{"--capabilities", "read"},
+			wantsMask:  1,
+			wantsNames: []string{"read"},
+		},
+		{
+			name:       "comma-separated accumulates",
+			args:       []string{"--capabilities", "read,write"},
+			wantsMask:  3,
+			wantsNames: []string{"read", "write"},
+		},
+		{
+			name:       "repeated flag accumulates",
+			args:       []string{"--capabilities", "read", "--capabilities", "admin"},
+			wantsMask:  5,
+			wantsNames: []string{"read", "admin"},
+		},
+		{
+			name:       "implies expands selection",
+			opts:       EnumOpts{Implies: map[string][]string{"admin": {"read", "write"}}},
+			args:       []string{"--capabilities", "admin"},
+			wantsMask:  7,
+			wantsNames: []string{"read", "write", "admin"},
+		},
+		{
+			name:       "conflicting values",
+			opts:       EnumOpts{Conflicts: map[string][]string{"admin": {"read"}}},
+			args:       []string{"--capabilities", "read,admin"},
+			wantsError: `invalid argument "read,admin" for "--capabilities" flag: "admin" conflicts with "read"`,
+		},
+		{
+			name:       "unknown value",
+			args:       []string{"--capabilities", "sudo"},
+			wantsError: `invalid argument "sudo" for "--capabilities" flag: invalid value "sudo": valid values are {read|write|admin}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mask uint64
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			BitmaskEnumFlag(cmd, &mask, "capabilities", "", options, tt.opts, "enabled capabilities")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsMask, mask)
+			assert.Equal(t, tt.wantsNames, BitmaskNames(mask, options))
+		})
+	}
+}
+
+func TestOrderedEnumFlagRequires(t *testing.T) {
+	requires := map[string][]string{
+		"test":   {"build"},
+		"deploy": {"test"},
+	}
+	tests := []struct {
+		name       string
+		args       []string
+		wantsSteps []string
+		wantsError string
+	}{
+		{
+			name:       "full chain",
+			args:       []string{"--steps", "build,test,deploy"},
+			wantsSteps: []string{"build", "test", "deploy"},
+		},
+		{
+			name:       "prefix of chain",
+			args:       []string{"--steps", "build,test"},
+			wantsSteps: []string{"build", "test"},
+		},
+		{
+			name:       "prerequisite in later use",
+			args:       []string{"--steps", "deploy", "--steps", "build,test"},
+			wantsSteps: []string{"deploy", "build", "test"},
+		},
+		{
+			name:       "missing direct prerequisite",
+			args:       []string{"--steps", "build,deploy"},
+			wantsError: "deploy requires test which was not selected",
+		},
+		{
+			name:       "missing transitive prerequisite",
+			args:       []string{"--steps", "test"},
+			wantsError: "test requires build which was not selected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var steps []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			OrderedEnumFlag(cmd, &steps, "steps", "", []string{"build", "test", "deploy"}, "pipeline steps", EnumOpts{Requires: requires})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsSteps, steps)
+		})
+	}
+}
+
+func TestStringSliceEnumFlagLenientUnknown(t *testing.T) {
+	tests := []struct {
+		name        string
+		lenient     bool
+		args        []string
+		wantsScopes []string
+		wantsError  string
+	}{
+		{
+			name:        "unknown stored when lenient",
+			lenient:     true,
+			args:        []string{"--scopes", "repo,codespace"},
+			wantsScopes: []string{"repo", "codespace"},
+		},
+		{
+			name:       "unknown rejected by default",
+			args:       []string{"--scopes", "repo,codespace"},
+			wantsError: `invalid argument "repo,codespace" for "--scopes" flag: invalid value "codespace": valid values are {repo|gist}`,
+		},
+		{
+			name:        "known values unaffected",
+			lenient:     true,
+			args:        []string{"--scopes", "repo,gist"},
+			wantsScopes: []string{"repo", "gist"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var scopes []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringSliceEnumFlag(cmd, &scopes, "scopes", "", nil, []string{"repo", "gist"}, "the scopes", EnumOpts{LenientUnknown: tt.lenient})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsScopes, scopes)
+		})
+	}
+}
+
+func TestOrderedEnumCountFlag(t *testing.T) {
+	options := []string{"t2.micro", "t2.large"}
+	tests := []struct {
+		name       string
+		args       []string
+		wantsPairs []EnumCount
+		wantsError string
+	}{
+		{
+			name: "pairs in order",
+			args: []string{"--instance-type", "t2.micro:2,t2.large:1"},
+			wantsPairs: []EnumCount{
+				{Value: "t2.micro", Count: 2},
+				{Value: "t2.large", Count: 1},
+			},
+		},
+		{
+			name: "repeated uses accumulate",
+			args: []string{"--instance-type", "t2.large:1", "--instance-type", "t2.micro:3"},
+			wantsPairs: []EnumCount{
+				{Value: "t2.large", Count: 1},
+				{Value: "t2.micro", Count: 3},
+			},
+		},
+		{
+			name:       "unknown type",
+			args:       []string{"--instance-type", "m5.xlarge:2"},
+			wantsError: `invalid argument "m5.xlarge:2" for "--instance-type" flag: invalid value "m5.xlarge": valid values are {t2.micro|t2.large}`,
+		},
+		{
+			name:       "non-positive count",
+			args:       []string{"--instance-type", "t2.micro:0"},
+			wantsError: `invalid argument "t2.micro:0" for "--instance-type" flag: invalid count "0" for "t2.micro": must be a positive integer`,
+		},
+		{
+			name:       "missing count",
+			args:       []string{"--instance-type", "t2.micro"},
+			wantsError: `invalid argument "t2.micro" for "--instance-type" flag: invalid entry "t2.micro": expected "option:count"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pairs []EnumCount
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			OrderedEnumCountFlag(cmd, &pairs, "instance-type", "", options, "instance types to provision")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsPairs, pairs)
+		})
+	}
+}
+
+func TestStringSliceEnumFlagAliasFile(t *testing.T) {
+	aliasFile := filepath.Join(t.TempDir(), "aliases.conf")
+	contents := "# region aliases\nus-east=virginia, nova\nus-west=oregon\n"
+	require.NoError(t, ioutil.WriteFile(aliasFile, []byte(contents), 0600))
+
+	tests := []struct {
+		name         string
+		aliasFile    string
+		args         []string
+		wantsRegions []string
+		wantsError   string
+	}{
+		{
+			name:         "aliases resolve to canonical",
+			aliasFile:    aliasFile,
+			args:         []string{"--regions", "virginia,oregon"},
+			wantsRegions: []string{"us-east", "us-west"},
+		},
+		{
+			name:         "alias and canonical collapse",
+			aliasFile:    aliasFile,
+			args:         []string{"--regions", "nova,us-east"},
+			wantsRegions: []string{"us-east"},
+		},
+		{
+			name:         "missing file means no aliases",
+			aliasFile:    filepath.Join(t.TempDir(), "nope.conf"),
+			args:         []string{"--regions", "us-east"},
+			wantsRegions: []string{"us-east"},
+		},
+		{
+			name:       "unknown value still rejected",
+			aliasFile:  aliasFile,
+			args:       []string{"--regions", "narnia"},
+			wantsError: `invalid argument "narnia" for "--regions" flag: invalid value "narnia": valid values are {us-east|us-west}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var regions []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringSliceEnumFlag(cmd, &regions, "regions", "", nil, []string{"us-east", "us-west"}, "the regions", EnumOpts{AliasFile: tt.aliasFile})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsRegions, regions)
+		})
+	}
+}
+
+func TestStringSliceEnumFlagRequiredGroups(t *testing.T) {
+	options := []string{"read", "list", "write", "delete"}
+	groups := [][]string{
+		{"read", "list"},
+		{"write", "delete"},
+	}
+	tests := []struct {
+		name       string
+		args       []string
+		wantsError string
+	}{
+		{
+			name: "one from each group",
+			args: []string{"--permissions", "read,write"},
+		},
+		{
+			name: "groups satisfied across uses",
+			args: []string{"--permissions", "list", "--permissions", "delete"},
+		},
+		{
+			name:       "missing write-ish group",
+			args:       []string{"--permissions", "read,list"},
+			wantsError: "must select at least one of {write, delete}",
+		},
+		{
+			name:       "missing read-ish group",
+			args:       []string{"--permissions", "write"},
+			wantsError: "must select at least one of {read, list}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var permissions []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringSliceEnumFlag(cmd, &permissions, "permissions", "", nil, options, "the permissions", EnumOpts{RequiredGroups: groups})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStringSliceEnumFlagConfirmValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantsError string
+	}{
+		{
+			name: "safe values need no confirmation",
+			args: []string{"--actions", "list,update"},
+		},
+		{
+			name:       "dangerous value without confirm",
+			args:       []string{"--actions", "update,delete"},
+			wantsError: "value \"delete\" for `--actions` requires --confirm",
+		},
+		{
+			name: "dangerous value with confirm",
+			args: []string{"--actions", "delete", "--confirm"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actions []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			cmd.Flags().Bool("confirm", false, "confirm dangerous actions")
+			StringSliceEnumFlag(cmd, &actions, "actions", "", nil, []string{"list", "update", "delete"}, "the actions", EnumOpts{ConfirmValues: []string{"delete"}})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestExcludeEnumFlag(t *testing.T) {
+	options := []string{"build", "lint", "vet", "test"}
+	tests := []struct {
+		name           string
+		args           []string
+		wantsRemaining []string
+		wantsError     string
+	}{
+		{
+			name:           "nothing excluded",
+			args:           []string{},
+			wantsRemaining: []string{"build", "lint", "vet", "test"},
+		},
+		{
+			name:           "complement in option order",
+			args:           []string{"--exclude-checks", "vet,lint"},
+			wantsRemaining: []string{"build", "test"},
+		},
+		{
+			name:           "repeated uses accumulate",
+			args:           []string{"--exclude-checks", "lint", "--exclude-checks", "test"},
+			wantsRemaining: []string{"build", "vet"},
+		},
+		{
+			name:       "unknown value",
+			args:       []string{"--exclude-checks", "format"},
+			wantsError: `invalid argument "format" for "--exclude-checks" flag: invalid value "format": valid values are {build|lint|vet|test}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var excluded ExcludeEnum
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			ExcludeEnumFlag(cmd, &excluded, "exclude-checks", "", options, "checks to skip")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsRemaining, excluded.Remaining())
+		})
+	}
+}
+
+func TestStringSliceEnumFlagAvailable(t *testing.T) {
+	available := map[string]func() bool{
+		"sso":      func() bool { return false },
+		"webhooks": func() bool { return true },
+	}
+	tests := []struct {
+		name       string
+		args       []string
+		wantsError string
+	}{
+		{
+			name: "ungated option",
+			args: []string{"--feature", "actions"},
+		},
+		{
+			name: "available gated option",
+			args: []string{"--feature", "webhooks"},
+		},
+		{
+			name:       "unavailable option",
+			args:       []string{"--feature", "actions,sso"},
+			wantsError: `feature "sso" is not available on your plan`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var features []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringSliceEnumFlag(cmd, &features, "feature", "", nil, []string{"actions", "webhooks", "sso"}, "the features", EnumOpts{Available: available})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAvailableEnumOptions(t *testing.T) {
+	options := []string{"actions", "webhooks", "sso"}
+	filtered := availableEnumOptions(options, map[string]func() bool{
+		"sso": func() bool { return false },
+	})
+	assert.Equal(t, []string{"actions", "webhooks"}, filtered)
+	assert.Equal(t, options, availableEnumOptions(options, nil))
+}
+
+func TestStringSliceEnumFlagCompanionFlags(t *testing.T) {
+	companions := map[string][]string{
+		"email": {"email-address"},
+		"slack": {"slack-webhook"},
+	}
+	tests := []struct {
+		name       string
+		args       []string
+		wantsError string
+	}{
+		{
+			name: "companion provided",
+			args: []string{"--notify", "email", "--email-address", "ops@example.com"},
+		},
+		{
+			name: "value without companions",
+			args: []string{"--notify", "sms"},
+		},
+		{
+			name:       "missing companion",
+			args:       []string{"--notify", "email,slack", "--email-address", "ops@example.com"},
+			wantsError: "notify=slack requires --slack-webhook",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notify []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			cmd.Flags().String("email-address", "", "")
+			cmd.Flags().String("slack-webhook", "", "")
+			StringSliceEnumFlag(cmd, &notify, "notify", "", nil, []string{"email", "slack", "sms"}, "notification channels", EnumOpts{CompanionFlags: companions})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCanonicalEnumSelection(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "sorted regardless of input order",
+			args: []string{"--type", "webhook,api", "--type", "cron"},
+			want: "api,cron,webhook",
+		},
+		{
+			name: "casing canonicalized",
+			args: []string{"--type", "API,Webhook"},
+			want: "api,webhook",
+		},
+		{
+			name: "duplicates collapse",
+			args: []string{"--type", "api", "--type", "api,API"},
+			want: "api",
+		},
+		{
+			name: "empty selection",
+			args: []string{},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var types []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			f := StringSliceEnumFlag(cmd, &types, "type", "", nil, []string{"api", "webhook", "cron"}, "types to include")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, CanonicalEnumSelection(f))
+		})
+	}
+}
+
+func TestEnumMinSelected(t *testing.T) {
+	options := []string{"build", "lint", "vet", "test"}
+	tests := []struct {
+		name    string
+		signed  bool
+		opts    SignedEnumOpts
+		exOpts  ExcludeEnumOpts
+		args    []string
+		wantErr string
+	}{
+		{
+			name:   "signed selection narrowed but nonempty",
+			signed: true,
+			args:   []string{"--targets", "-lint"},
+		},
+		{
+			name:    "signed selection emptied",
+			signed:  true,
+			args:    []string{"--targets", "-build,-lint"},
+			wantErr: "no targets selected after applying exclusions",
+		},
+		{
+			name:    "signed selection below configured minimum",
+			signed:  true,
+			opts:    SignedEnumOpts{MinSelected: 2},
+			args:    []string{"--targets", "-lint"},
+			wantErr: "only 1 targets selected after applying exclusions, need at least 2",
+		},
+		{
+			name:    "excluding everything",
+			args:    []string{"--targets", "build,lint,vet,test"},
+			wantErr: "no targets selected after applying exclusions",
+		},
+		{
+			name: "excluding some",
+			args: []string{"--targets", "lint,vet"},
+		},
+		{
+			name:    "exclusion below configured minimum",
+			exOpts:  ExcludeEnumOpts{MinSelected: 3},
+			args:    []string{"--targets", "lint,vet"},
+			wantErr: "only 2 targets selected after applying exclusions, need at least 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			if tt.signed {
+				var targets []string
+				SignedEnumFlag(cmd, &targets, "targets", "", []string{"build", "lint"}, options, "targets to act on", tt.opts)
+			} else {
+				var targets ExcludeEnum
+				ExcludeEnumFlag(cmd, &targets, "targets", "", options, "targets to skip", tt.exOpts)
+			}
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEnumCountOccurrences(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantCounts map[string]int
+		wantErr    string
+	}{
+		{
+			name:       "repetition counted",
+			args:       []string{"--vote", "a,a,b"},
+			wantCounts: map[string]int{"a": 2, "b": 1},
+		},
+		{
+			name:       "repeated uses accumulate",
+			args:       []string{"--vote", "a", "--vote", "a,c"},
+			wantCounts: map[string]int{"a": 2, "c": 1},
+		},
+		{
+			name:    "still validated against options",
+			args:    []string{"--vote", "a,z"},
+			wantErr: `invalid argument "a,z" for "--vote" flag: invalid value "z": valid values are {a|b|c}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var votes []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			f := StringSliceEnumFlag(cmd, &votes, "vote", "", nil, []string{"a", "b", "c"}, "votes to cast", EnumOpts{CountOccurrences: true})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCounts, EnumSelectionCounts(f))
+		})
+	}
+}
+
+func TestEnumValidWindows(t *testing.T) {
+	now := time.Now()
+	windows := map[string]EnumWindow{
+		"promo":   {From: now.Add(-time.Hour), Until: now.Add(time.Hour)},
+		"expired": {Until: now.Add(-time.Hour)},
+		"future":  {From: now.Add(time.Hour)},
+	}
+	options := []string{"standard", "promo", "expired", "future"}
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name: "open window",
+			args: []string{"--plan", "promo"},
+		},
+		{
+			name: "no window",
+			args: []string{"--plan", "standard"},
+		},
+		{
+			name:    "expired option",
+			args:    []string{"--plan", "expired"},
+			wantErr: `option "expired" is not currently available`,
+		},
+		{
+			name:    "future option",
+			args:    []string{"--plan", "future"},
+			wantErr: `option "future" is not currently available`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var plans []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringSliceEnumFlag(cmd, &plans, "plan", "", nil, options, "plans to apply", EnumOpts{ValidWindows: windows})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("completion hides closed windows", func(t *testing.T) {
+		assert.Equal(t, []string{"standard", "promo"}, windowedEnumOptions(options, windows))
+	})
+}
+
+func TestEnumIDs(t *testing.T) {
+	ids := map[string]string{
+		"cache": "plugin-cache-v2",
+		"auth":  "plugin-auth-v1",
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		wants   []string
+		wantErr string
+	}{
+		{
+			name:  "friendly names stored as IDs",
+			args:  []string{"--plugin", "cache,auth"},
+			wants: []string{"plugin-cache-v2", "plugin-auth-v1"},
+		},
+		{
+			name:  "unmapped option stored as given",
+			args:  []string{"--plugin", "metrics"},
+			wants: []string{"metrics"},
+		},
+		{
+			name:  "repeated friendly names dedup on ID",
+			args:  []string{"--plugin", "cache", "--plugin", "Cache"},
+			wants: []string{"plugin-cache-v2"},
+		},
+		{
+			name:    "errors use friendly names",
+			args:    []string{"--plugin", "caching"},
+			wantErr: `invalid argument "caching" for "--plugin" flag: invalid value "caching": valid values are {cache|auth|metrics}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var plugins []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringSliceEnumFlag(cmd, &plugins, "plugin", "", nil, []string{"cache", "auth", "metrics"}, "plugins to enable", EnumOpts{IDs: ids})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wants, plugins)
+		})
+	}
+}
+
+func TestEnumIDsChecksUseFriendlyNames(t *testing.T) {
+	ids := map[string]string{
+		"cache": "plugin-cache-v2",
+		"auth":  "plugin-auth-v1",
+	}
+
+	t.Run("confirm values match what the user typed", func(t *testing.T) {
+		var plugins []string
+		cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+		cmd.Flags().Bool("confirm", false, "")
+		StringSliceEnumFlag(cmd, &plugins, "plugin", "", nil, []string{"cache", "auth", "metrics"}, "plugins to enable", EnumOpts{
+			IDs:           ids,
+			ConfirmValues: []string{"auth"},
+		})
+		cmd.SetArgs([]string{"--plugin", "auth"})
+		cmd.SetOut(ioutil.Discard)
+		cmd.SetErr(ioutil.Discard)
+		_, err := cmd.ExecuteC()
+		assert.EqualError(t, err, "value \"auth\" for `--plugin` requires --confirm")
+	})
+
+	t.Run("availability matches what the user typed", func(t *testing.T) {
+		var plugins []string
+		cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+		StringSliceEnumFlag(cmd, &plugins, "plugin", "", nil, []string{"cache", "auth", "metrics"}, "plugins to enable", EnumOpts{
+			IDs:       ids,
+			Available: map[string]func() bool{"cache": func() bool { return false }},
+		})
+		cmd.SetArgs([]string{"--plugin", "cache"})
+		cmd.SetOut(ioutil.Discard)
+		cmd.SetErr(ioutil.Discard)
+		_, err := cmd.ExecuteC()
+		assert.EqualError(t, err, `plugin "cache" is not available on your plan`)
+	})
+
+	t.Run("required groups match what the user typed", func(t *testing.T) {
+		var plugins []string
+		cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+		StringSliceEnumFlag(cmd, &plugins, "plugin", "", nil, []string{"cache", "auth", "metrics"}, "plugins to enable", EnumOpts{
+			IDs:            ids,
+			RequiredGroups: [][]string{{"cache", "auth"}},
+		})
+		cmd.SetArgs([]string{"--plugin", "auth"})
+		cmd.SetOut(ioutil.Discard)
+		cmd.SetErr(ioutil.Discard)
+		_, err := cmd.ExecuteC()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"plugin-auth-v1"}, plugins)
+	})
+}
+
+func TestModalEnumFlag(t *testing.T) {
+	modeOptions := map[string][]string{
+		"a": {"x", "y"},
+		"b": {"p", "q"},
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		wants   string
+		wantErr string
+	}{
+		{
+			name:  "valid for mode a",
+			args:  []string{"--mode", "a", "--value", "x"},
+			wants: "x",
+		},
+		{
+			name:  "valid for mode b",
+			args:  []string{"--mode", "b", "--value", "q"},
+			wants: "q",
+		},
+		{
+			name:    "wrong mode for value",
+			args:    []string{"--mode", "b", "--value", "x"},
+			wantErr: "invalid value \"x\" for `--value` when `--mode` is \"b\": valid values are {p|q}",
+		},
+		{
+			name:    "unknown mode",
+			args:    []string{"--mode", "c", "--value", "x"},
+			wantErr: "--value is not valid when `--mode` is \"c\"",
+		},
+		{
+			name: "unset value skips validation",
+			args: []string{"--mode", "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mode, value string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			cmd.Flags().StringVar(&mode, "mode", "", "operating mode")
+			ModalEnumFlag(cmd, &value, "value", "", "mode", modeOptions, "mode-specific value")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wants, value)
+		})
+	}
+}
+
+func TestEnumOptsUnsupported(t *testing.T) {
+	t.Run("unsupported option panics", func(t *testing.T) {
+		var types []string
+		cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+		assert.PanicsWithValue(t, "cmdutil: StringSliceEnumFlag does not support EnumOpts.Conflicts", func() {
+			StringSliceEnumFlag(cmd, &types, "type", "", nil, []string{"a", "b"}, "types",
+				EnumOpts{Conflicts: map[string][]string{"a": {"b"}}})
+		})
+	})
+
+	t.Run("unsupported option panics on single-value flag", func(t *testing.T) {
+		var mode string
+		cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+		assert.PanicsWithValue(t, "cmdutil: StringEnumFlag does not support EnumOpts.Requires", func() {
+			StringEnumFlag(cmd, &mode, "mode", "", "", []string{"a", "b"}, "mode",
+				EnumOpts{Requires: map[string][]string{"a": {"b"}}})
+		})
+	})
+
+	t.Run("supported options register", func(t *testing.T) {
+		var types []string
+		cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+		assert.NotPanics(t, func() {
+			StringSliceEnumFlag(cmd, &types, "type", "", nil, []string{"a", "b"}, "types",
+				EnumOpts{Categories: map[string]string{"a": "first"}})
+		})
+	})
+}
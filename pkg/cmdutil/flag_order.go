@@ -0,0 +1,124 @@
+package cmdutil
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// FlagOccurrence records a single use of a tracked flag on the command line.
+type FlagOccurrence struct {
+	Name  string
+	Value string
+}
+
+// TrackFlagOrder arranges for each use of the named flags to be recorded in the order
+// it appears on the command line, which pflag does not preserve across flags. Call it
+// after the flags are registered and before parsing; retrieve the order afterwards
+// with OrderedFlags. The recording lives on the wrapped flag values themselves, so it
+// is released along with the command.
+func TrackFlagOrder(cmd *cobra.Command, names ...string) {
+	fs := cmd.Flags()
+	occurrences := trackedOccurrences(fs)
+	if occurrences == nil {
+		occurrences = &[]FlagOccurrence{}
+	}
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil {
+			continue
+		}
+		rec := &orderRecordingValue{inner: f.Value, name: name, occurrences: occurrences}
+		if _, ok := f.Value.(pflag.SliceValue); ok {
+			f.Value = &orderRecordingSliceValue{orderRecordingValue: rec}
+		} else {
+			f.Value = rec
+		}
+	}
+}
+
+// OrderedFlags returns the recorded uses of flags tracked with TrackFlagOrder, in
+// command-line order. When names are given, the result is filtered to those flags.
+func OrderedFlags(cmd *cobra.Command, names ...string) []FlagOccurrence {
+	occurrences := trackedOccurrences(cmd.Flags())
+	if occurrences == nil {
+		return nil
+	}
+	if len(names) == 0 {
+		return *occurrences
+	}
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var filtered []FlagOccurrence
+	for _, o := range *occurrences {
+		if wanted[o.Name] {
+			filtered = append(filtered, o)
+		}
+	}
+	return filtered
+}
+
+// trackedOccurrences finds the occurrence list shared by the flag set's tracked flags,
+// or nil when none are tracked yet.
+func trackedOccurrences(fs *pflag.FlagSet) *[]FlagOccurrence {
+	var occurrences *[]FlagOccurrence
+	fs.VisitAll(func(f *pflag.Flag) {
+		if occurrences != nil {
+			return
+		}
+		if rec, ok := f.Value.(orderRecorder); ok {
+			occurrences = rec.order()
+		}
+	})
+	return occurrences
+}
+
+type orderRecorder interface {
+	order() *[]FlagOccurrence
+}
+
+type orderRecordingValue struct {
+	inner       pflag.Value
+	name        string
+	occurrences *[]FlagOccurrence
+}
+
+func (v *orderRecordingValue) Set(value string) error {
+	if err := v.inner.Set(value); err != nil {
+		return err
+	}
+	*v.occurrences = append(*v.occurrences, FlagOccurrence{Name: v.name, Value: value})
+	return nil
+}
+
+func (v *orderRecordingValue) String() string {
+	return v.inner.String()
+}
+
+func (v *orderRecordingValue) Type() string {
+	return v.inner.Type()
+}
+
+func (v *orderRecordingValue) order() *[]FlagOccurrence {
+	return v.occurrences
+}
+
+// orderRecordingSliceValue wraps multi-value flags, forwarding pflag.SliceValue so that
+// consumers like flagSliceValues still see the parsed elements rather than splitting
+// the rendered String().
+type orderRecordingSliceValue struct {
+	*orderRecordingValue
+}
+
+func (v *orderRecordingSliceValue) GetSlice() []string {
+	return v.inner.(pflag.SliceValue).GetSlice()
+}
+
+func (v *orderRecordingSliceValue) Append(value string) error {
+	return v.inner.(pflag.SliceValue).Append(value)
+}
+
+func (v *orderRecordingSliceValue) Replace(values []string) error {
+	return v.inner.(pflag.SliceValue).Replace(values)
+}
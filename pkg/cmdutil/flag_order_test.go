@@ -0,0 +1,61 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedFlags(t *testing.T) {
+	var adds, removes []string
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	cmd.Flags().StringArrayVar(&adds, "add", nil, "add an item")
+	cmd.Flags().StringArrayVar(&removes, "remove", nil, "remove an item")
+	TrackFlagOrder(cmd, "add", "remove")
+
+	cmd.SetArgs([]string{"--add", "x", "--remove", "y", "--add", "z"})
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+	_, err := cmd.ExecuteC()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []FlagOccurrence{
+		{Name: "add", Value: "x"},
+		{Name: "remove", Value: "y"},
+		{Name: "add", Value: "z"},
+	}, OrderedFlags(cmd))
+	assert.Equal(t, []FlagOccurrence{
+		{Name: "remove", Value: "y"},
+	}, OrderedFlags(cmd, "remove"))
+
+	// the underlying values still accumulate normally
+	assert.Equal(t, []string{"x", "z"}, adds)
+	assert.Equal(t, []string{"y"}, removes)
+}
+
+func TestTrackFlagOrderPreservesSliceValues(t *testing.T) {
+	var regions, primary []string
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	cmd.Flags().StringArrayVar(&regions, "regions", nil, "regions")
+	cmd.Flags().StringArrayVar(&primary, "primary", nil, "primary regions")
+	TrackFlagOrder(cmd, "regions", "primary")
+	MarkFlagSubsetOf(cmd, "primary", "regions")
+
+	// "us,eu" is a single stringArray element; comma-splitting String() would
+	// misreport it as two
+	cmd.SetArgs([]string{"--regions", "us,eu", "--primary", "us,eu"})
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+	_, err := cmd.ExecuteC()
+	assert.NoError(t, err)
+
+	values, err := flagSliceValues(cmd, "regions")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"us,eu"}, values)
+	assert.Equal(t, []FlagOccurrence{
+		{Name: "regions", Value: "us,eu"},
+		{Name: "primary", Value: "us,eu"},
+	}, OrderedFlags(cmd))
+}
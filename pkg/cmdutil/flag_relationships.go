@@ -0,0 +1,269 @@
+package cmdutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// registerFlagCheck installs fn to run after flag parsing, chaining in front of any
+// PreRunE the command already has.
+func registerFlagCheck(cmd *cobra.Command, fn func(*cobra.Command) error) {
+	existing := cmd.PreRunE
+	cmd.PreRunE = func(c *cobra.Command, args []string) error {
+		if err := fn(c); err != nil {
+			return err
+		}
+		if existing != nil {
+			return existing(c, args)
+		}
+		return nil
+	}
+}
+
+// MarkFlagSubsetOf constrains the multi-value flag subFlag so that every one of its
+// elements must also be present in the multi-value flag superFlag, e.g. `--primary`
+// being a subset of `--regions`. Comparison is case-insensitive to match enum flag
+// semantics. The check runs in PreRunE once both flags are parsed.
+func MarkFlagSubsetOf(cmd *cobra.Command, subFlag, superFlag string) {
+	registerFlagCheck(cmd, func(c *cobra.Command) error {
+		sub, err := flagSliceValues(c, subFlag)
+		if err != nil {
+			return err
+		}
+		super, err := flagSliceValues(c, superFlag)
+		if err != nil {
+			return err
+		}
+		for _, element := range sub {
+			if !isEnumOption(element, super) {
+				return FlagErrorf("%q in `--%s` is not one of the values of `--%s`", element, subFlag, superFlag)
+			}
+		}
+		return nil
+	})
+}
+
+// MarkFlagDivides requires that the value of the int flag divisorFlag divides the
+// value of the int flag dividendFlag evenly, e.g. `--shards` dividing `--total`. The
+// check runs in PreRunE once both flags are parsed, and is skipped when neither flag
+// was passed so that defaults are not validated.
+func MarkFlagDivides(cmd *cobra.Command, divisorFlag, dividendFlag string) {
+	registerFlagCheck(cmd, func(c *cobra.Command) error {
+		if !anyFlagChanged(c, divisorFlag, dividendFlag) {
+			return nil
+		}
+		divisor, err := c.Flags().GetInt(divisorFlag)
+		if err != nil {
+			return err
+		}
+		dividend, err := c.Flags().GetInt(dividendFlag)
+		if err != nil {
+			return err
+		}
+		if divisor == 0 {
+			return FlagErrorf("`--%s` must not be zero", divisorFlag)
+		}
+		if dividend%divisor != 0 {
+			return FlagErrorf("`--%s` (%d) must divide `--%s` (%d) evenly", divisorFlag, divisor, dividendFlag, dividend)
+		}
+		return nil
+	})
+}
+
+// MarkFlagGreaterOrEqual requires that the value of greaterFlag is at least the value
+// of lesserFlag, e.g. `--end` not preceding `--start`. The values are compared by the
+// flags' declared type: ints numerically, durations as time.Duration, and times as
+// RFC 3339 timestamps; anything else falls back to string comparison. The check runs
+// in PreRunE once both flags are parsed, and is skipped when neither flag was passed
+// so that defaults are not validated.
+func MarkFlagGreaterOrEqual(cmd *cobra.Command, greaterFlag, lesserFlag string) {
+	registerFlagCheck(cmd, func(c *cobra.Command) error {
+		if !anyFlagChanged(c, greaterFlag, lesserFlag) {
+			return nil
+		}
+		greater := c.Flags().Lookup(greaterFlag)
+		if greater == nil {
+			return FlagErrorf("no such flag: --%s", greaterFlag)
+		}
+		lesser := c.Flags().Lookup(lesserFlag)
+		if lesser == nil {
+			return FlagErrorf("no such flag: --%s", lesserFlag)
+		}
+		ok, err := flagValueAtLeast(greater, lesser)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return FlagErrorf("`--%s` (%s) must be greater than or equal to `--%s` (%s)",
+				greaterFlag, greater.Value.String(), lesserFlag, lesser.Value.String())
+		}
+		return nil
+	})
+}
+
+// anyFlagChanged reports whether any of the named flags was explicitly passed.
+func anyFlagChanged(cmd *cobra.Command, names ...string) bool {
+	for _, name := range names {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+			return true
+		}
+	}
+	return false
+}
+
+// flagValueAtLeast reports whether a's value is >= b's, comparing by the flags' type.
+func flagValueAtLeast(a, b *pflag.Flag) (bool, error) {
+	as, bs := a.Value.String(), b.Value.String()
+	switch a.Value.Type() {
+	case "int", "int8", "int16", "int32", "int64":
+		an, err := strconv.ParseInt(as, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		bn, err := strconv.ParseInt(bs, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return an >= bn, nil
+	case "duration":
+		ad, err := time.ParseDuration(as)
+		if err != nil {
+			return false, err
+		}
+		bd, err := time.ParseDuration(bs)
+		if err != nil {
+			return false, err
+		}
+		return ad >= bd, nil
+	case "time", "timestamp":
+		at, err := time.Parse(time.RFC3339, as)
+		if err != nil {
+			return false, err
+		}
+		bt, err := time.Parse(time.RFC3339, bs)
+		if err != nil {
+			return false, err
+		}
+		return !at.Before(bt), nil
+	default:
+		return as >= bs, nil
+	}
+}
+
+// MarkFlagUniqueAgainst validates in PreRunE that the flag's value does not already
+// name an existing resource, as reported by exists. This centralizes the pre-create
+// uniqueness check; exists is injectable so the lookup can be stubbed in tests. Lookup
+// failures are propagated as-is.
+func MarkFlagUniqueAgainst(cmd *cobra.Command, name string, exists func(string) (bool, error)) {
+	registerFlagCheck(cmd, func(c *cobra.Command) error {
+		f := c.Flags().Lookup(name)
+		if f == nil || !f.Changed {
+			return nil
+		}
+		value := f.Value.String()
+		taken, err := exists(value)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return FlagErrorf("%s %q is already in use", name, value)
+		}
+		return nil
+	})
+}
+
+// PathHierarchy selects the relationship MarkFlagPathHierarchy enforces among the
+// elements of a path-valued multi-value flag.
+type PathHierarchy int
+
+const (
+	// PathsDisjoint forbids any selected path from being an ancestor of another,
+	// preventing redundant overlapping selections.
+	PathsDisjoint PathHierarchy = iota
+	// PathsChain requires the selected paths to form a single ancestor chain, e.g.
+	// "a", "a/b", "a/b/c".
+	PathsChain
+)
+
+// MarkFlagPathHierarchy validates the hierarchy relationship among the elements of a
+// slash-separated path slice flag. The check runs in PreRunE once the flag is parsed.
+func MarkFlagPathHierarchy(cmd *cobra.Command, name string, relation PathHierarchy) {
+	registerFlagCheck(cmd, func(c *cobra.Command) error {
+		paths, err := flagSliceValues(c, name)
+		if err != nil {
+			return err
+		}
+		for i, a := range paths {
+			for _, b := range paths[i+1:] {
+				ancestor := pathIsAncestor(a, b) || pathIsAncestor(b, a)
+				switch relation {
+				case PathsDisjoint:
+					if pathIsAncestor(a, b) {
+						return FlagErrorf("%q is an ancestor of %q", a, b)
+					}
+					if pathIsAncestor(b, a) {
+						return FlagErrorf("%q is an ancestor of %q", b, a)
+					}
+				case PathsChain:
+					if !ancestor {
+						return FlagErrorf("%q and %q do not form an ancestor chain", a, b)
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// MarkFlagTotalSize validates in PreRunE that the combined serialized length of all
+// elements of a multi-value flag stays within maxBytes, e.g. a 16KB backend limit
+// across every `--metadata key=value`. Per-element checks cannot catch this since
+// elements accumulate across uses of the flag.
+func MarkFlagTotalSize(cmd *cobra.Command, name string, maxBytes int) {
+	registerFlagCheck(cmd, func(c *cobra.Command) error {
+		elements, err := flagSliceValues(c, name)
+		if err != nil {
+			return err
+		}
+		total := 0
+		for _, element := range elements {
+			total += len(element)
+		}
+		if total > maxBytes {
+			return FlagErrorf("%s exceeds %s (got %s)", name, formatKilobytes(maxBytes), formatKilobytes(total))
+		}
+		return nil
+	})
+}
+
+// formatKilobytes renders a byte count in KB, rounding up, matching how backend limits
+// are documented.
+func formatKilobytes(n int) string {
+	return fmt.Sprintf("%dKB", (n+1023)/1024)
+}
+
+// pathIsAncestor reports whether ancestor strictly contains path in a slash-separated
+// hierarchy.
+func pathIsAncestor(ancestor, path string) bool {
+	return path != ancestor && strings.HasPrefix(path, strings.TrimSuffix(ancestor, "/")+"/")
+}
+
+// flagSliceValues returns the parsed elements of a multi-value flag.
+func flagSliceValues(cmd *cobra.Command, name string) ([]string, error) {
+	f := cmd.Flags().Lookup(name)
+	if f == nil {
+		return nil, FlagErrorf("no such flag: --%s", name)
+	}
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return sv.GetSlice(), nil
+	}
+	if s := f.Value.String(); s != "" {
+		return strings.Split(s, ","), nil
+	}
+	return nil, nil
+}
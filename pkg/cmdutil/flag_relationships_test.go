@@ -0,0 +1,314 @@
+package cmdutil
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkFlagUniqueAgainst(t *testing.T) {
+	taken := map[string]bool{"existing": true}
+	tests := []struct {
+		name       string
+		args       []string
+		existsErr  error
+		wantsError string
+	}{
+		{
+			name: "unused name",
+			args: []string{"--name", "fresh"},
+		},
+		{
+			name: "flag not passed",
+			args: []string{},
+		},
+		{
+			name:       "name already in use",
+			args:       []string{"--name", "existing"},
+			wantsError: `name "existing" is already in use`,
+		},
+		{
+			name:       "lookup failure propagates",
+			args:       []string{"--name", "fresh"},
+			existsErr:  errors.New("api unavailable"),
+			wantsError: "api unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var name string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			cmd.Flags().StringVar(&name, "name", "", "the name")
+			MarkFlagUniqueAgainst(cmd, "name", func(value string) (bool, error) {
+				return taken[value], tt.existsErr
+			})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMarkFlagDivides(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantsError string
+	}{
+		{
+			name: "divides evenly",
+			args: []string{"--shards", "4", "--total", "12"},
+		},
+		{
+			name:       "does not divide",
+			args:       []string{"--shards", "5", "--total", "12"},
+			wantsError: "`--shards` (5) must divide `--total` (12) evenly",
+		},
+		{
+			name:       "zero divisor",
+			args:       []string{"--shards", "0", "--total", "12"},
+			wantsError: "`--shards` must not be zero",
+		},
+		{
+			name: "neither flag set skips validation",
+			args: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var shards, total int
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			cmd.Flags().IntVar(&shards, "shards", 0, "shard count")
+			cmd.Flags().IntVar(&total, "total", 1, "total count")
+			MarkFlagDivides(cmd, "shards", "total")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMarkFlagSubsetOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantsError string
+	}{
+		{
+			name: "subset",
+			args: []string{"--regions", "us,eu", "--primary", "eu"},
+		},
+		{
+			name: "case-insensitive membership",
+			args: []string{"--regions", "us,EU", "--primary", "eu"},
+		},
+		{
+			name: "neither flag set",
+			args: []string{},
+		},
+		{
+			name:       "not a subset",
+			args:       []string{"--regions", "us,eu", "--primary", "apac"},
+			wantsError: "\"apac\" in `--primary` is not one of the values of `--regions`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var regions, primary []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			cmd.Flags().StringSliceVar(&regions, "regions", nil, "all regions")
+			cmd.Flags().StringSliceVar(&primary, "primary", nil, "primary regions")
+			MarkFlagSubsetOf(cmd, "primary", "regions")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMarkFlagGreaterOrEqual(t *testing.T) {
+	tests := []struct {
+		name       string
+		register   func(cmd *cobra.Command)
+		args       []string
+		wantsError string
+	}{
+		{
+			name: "ints in order",
+			register: func(cmd *cobra.Command) {
+				cmd.Flags().Int("start", 0, "")
+				cmd.Flags().Int("end", 0, "")
+			},
+			args: []string{"--start", "1", "--end", "5"},
+		},
+		{
+			name: "ints equal",
+			register: func(cmd *cobra.Command) {
+				cmd.Flags().Int("start", 0, "")
+				cmd.Flags().Int("end", 0, "")
+			},
+			args: []string{"--start", "5", "--end", "5"},
+		},
+		{
+			name: "ints out of order",
+			register: func(cmd *cobra.Command) {
+				cmd.Flags().Int("start", 0, "")
+				cmd.Flags().Int("end", 0, "")
+			},
+			args:       []string{"--start", "10", "--end", "5"},
+			wantsError: "`--end` (5) must be greater than or equal to `--start` (10)",
+		},
+		{
+			name: "durations out of order",
+			register: func(cmd *cobra.Command) {
+				cmd.Flags().Duration("start", 0, "")
+				cmd.Flags().Duration("end", 0, "")
+			},
+			args:       []string{"--start", "2h", "--end", "30m"},
+			wantsError: "`--end` (30m0s) must be greater than or equal to `--start` (2h0m0s)",
+		},
+		{
+			name: "durations in order",
+			register: func(cmd *cobra.Command) {
+				cmd.Flags().Duration("start", 0, "")
+				cmd.Flags().Duration("end", 0, "")
+			},
+			args: []string{"--start", "30m", "--end", "2h"},
+		},
+		{
+			name: "neither flag set skips validation",
+			register: func(cmd *cobra.Command) {
+				cmd.Flags().Int("start", 5, "")
+				cmd.Flags().Int("end", 0, "")
+			},
+			args: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			tt.register(cmd)
+			MarkFlagGreaterOrEqual(cmd, "end", "start")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMarkFlagPathHierarchy(t *testing.T) {
+	tests := []struct {
+		name       string
+		relation   PathHierarchy
+		args       []string
+		wantsError string
+	}{
+		{
+			name:     "disjoint paths allowed",
+			relation: PathsDisjoint,
+			args:     []string{"--paths", "a/b,a/c,d"},
+		},
+		{
+			name:       "ancestor forbidden",
+			relation:   PathsDisjoint,
+			args:       []string{"--paths", "a,a/b"},
+			wantsError: `"a" is an ancestor of "a/b"`,
+		},
+		{
+			name:     "chain accepted",
+			relation: PathsChain,
+			args:     []string{"--paths", "a,a/b,a/b/c"},
+		},
+		{
+			name:       "broken chain rejected",
+			relation:   PathsChain,
+			args:       []string{"--paths", "a/b,a/c"},
+			wantsError: `"a/b" and "a/c" do not form an ancestor chain`,
+		},
+		{
+			name:     "similar prefix is not an ancestor",
+			relation: PathsDisjoint,
+			args:     []string{"--paths", "a,ab"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			cmd.Flags().StringSlice("paths", nil, "the paths")
+			MarkFlagPathHierarchy(cmd, "paths", tt.relation)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMarkFlagTotalSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name: "under the limit",
+			args: []string{"--metadata", "team=platform", "--metadata", "env=prod"},
+		},
+		{
+			name:    "over the limit",
+			args:    []string{"--metadata", "blob=" + strings.Repeat("x", 18*1024-len("blob="))},
+			wantErr: "metadata exceeds 16KB (got 18KB)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var metadata []string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			cmd.Flags().StringArrayVar(&metadata, "metadata", nil, "metadata entries")
+			MarkFlagTotalSize(cmd, "metadata", 16*1024)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -2,9 +2,13 @@ package cmdutil
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/cli/cli/v2/pkg/prompt"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -23,17 +27,121 @@ func NilBoolFlag(cmd *cobra.Command, p **bool, name string, shorthand string, us
 	return f
 }
 
+// ExplicitBool is a boolean flag value that additionally records whether the flag was
+// explicitly set, which is useful when merging flag values with configuration.
+type ExplicitBool struct {
+	Value bool
+	Set   bool
+}
+
+// ExplicitBoolFlag defines a new bool flag that records both the value and whether it
+// was explicitly passed. This avoids the double-pointer awkwardness of NilBoolFlag for
+// config-merge code.
+func ExplicitBoolFlag(cmd *cobra.Command, p *ExplicitBool, name string, shorthand string, usage string) *pflag.Flag {
+	f := cmd.Flags().VarPF(&explicitBoolValue{p}, name, shorthand, usage)
+	f.NoOptDefVal = "true"
+	return f
+}
+
 // StringEnumFlag defines a new string flag that only allows values listed in options.
-func StringEnumFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue string, options []string, usage string) *pflag.Flag {
+// At most one EnumOpts may be passed to customize validation.
+func StringEnumFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue string, options []string, usage string, opts ...EnumOpts) *pflag.Flag {
 	*p = defaultValue
 	val := &enumValue{string: p, options: options}
+	if len(opts) > 0 {
+		assertSupportedEnumOpts("StringEnumFlag", opts[0],
+			"AllowWildcard", "PromptWhenUnset", "Prompter", "Interactive",
+			"Available", "ValidWindows")
+		val.opts = opts[0]
+	}
+	if val.opts.PromptWhenUnset {
+		registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+			return promptForEnumValue(cmd, name, val)
+		})
+	}
+	if len(val.opts.Available) > 0 {
+		registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+			return checkEnumAvailable(name, []string{*p}, val.opts.Available)
+		})
+	}
+	if len(val.opts.ValidWindows) > 0 {
+		registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+			return checkEnumWindows([]string{*p}, val.opts.ValidWindows)
+		})
+	}
 	f := cmd.Flags().VarPF(val, name, shorthand, fmt.Sprintf("%s: %s", usage, formatValuesForUsageDocs(options)))
 	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return options, cobra.ShellCompDirectiveNoFileComp
+		usable := availableEnumOptions(options, val.opts.Available)
+		return windowedEnumOptions(usable, val.opts.ValidWindows), cobra.ShellCompDirectiveNoFileComp
 	})
 	return f
 }
 
+// promptForEnumValue interactively selects a value for an unset enum flag, re-prompting
+// while the chosen value fails validation. Outside an interactive session the unset
+// flag is an error instead.
+func promptForEnumValue(cmd *cobra.Command, name string, val *enumValue) error {
+	if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+		return nil
+	}
+	interactive := val.opts.Interactive
+	if interactive == nil {
+		interactive = func() bool {
+			return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+		}
+	}
+	if !interactive() {
+		return FlagErrorf("--%s required when not running interactively", name)
+	}
+	prompter := val.opts.Prompter
+	if prompter == nil {
+		prompter = func(message string, options []string) (string, error) {
+			var result string
+			err := prompt.SurveyAskOne(&survey.Select{Message: message, Options: options}, &result)
+			return result, err
+		}
+	}
+	for {
+		choice, err := prompter(fmt.Sprintf("Select a value for --%s", name), val.options)
+		if err != nil {
+			return err
+		}
+		if err := val.Set(choice); err == nil {
+			return nil
+		}
+	}
+}
+
+// CoerceEmptyToDefault wraps a registered flag so that setting it to an empty value
+// resets it to its default instead of storing "". This is useful when scripts pass
+// empty expansions (e.g. `--env "$FOO"`) to mean "use the default". It is meant for
+// ordinary flags; do not combine it with NilStringFlag, whose empty value carries
+// distinct "explicitly blank" semantics.
+func CoerceEmptyToDefault(f *pflag.Flag) *pflag.Flag {
+	f.Value = &emptyToDefaultValue{inner: f.Value, def: f.DefValue}
+	return f
+}
+
+type emptyToDefaultValue struct {
+	inner pflag.Value
+	def   string
+}
+
+func (v *emptyToDefaultValue) Set(value string) error {
+	if value == "" {
+		return v.inner.Set(v.def)
+	}
+	return v.inner.Set(value)
+}
+
+func (v *emptyToDefaultValue) String() string {
+	return v.inner.String()
+}
+
+func (v *emptyToDefaultValue) Type() string {
+	return v.inner.Type()
+}
+
 func formatValuesForUsageDocs(values []string) string {
 	return fmt.Sprintf("{%s}", strings.Join(values, "|"))
 }
@@ -93,12 +201,40 @@ func (b *boolValue) IsBoolFlag() bool {
 	return true
 }
 
+type explicitBoolValue struct {
+	bool *ExplicitBool
+}
+
+func (b *explicitBoolValue) Set(value string) error {
+	v, err := strconv.ParseBool(value)
+	b.bool.Value = v
+	b.bool.Set = true
+	return err
+}
+
+func (b *explicitBoolValue) String() string {
+	return strconv.FormatBool(b.bool.Value)
+}
+
+func (b *explicitBoolValue) Type() string {
+	return "bool"
+}
+
+func (b *explicitBoolValue) IsBoolFlag() bool {
+	return true
+}
+
 type enumValue struct {
 	string  *string
 	options []string
+	opts    EnumOpts
 }
 
 func (e *enumValue) Set(value string) error {
+	if e.opts.AllowWildcard && value == EnumWildcard {
+		*e.string = value
+		return nil
+	}
 	found := false
 	for _, opt := range e.options {
 		if strings.EqualFold(opt, value) {
@@ -0,0 +1,153 @@
+package cmdutil
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoerceEmptyToDefault(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantsValue string
+	}{
+		{
+			name:       "not passed",
+			args:       []string{},
+			wantsValue: "main",
+		},
+		{
+			name:       "explicit value",
+			args:       []string{"--branch", "dev"},
+			wantsValue: "dev",
+		},
+		{
+			name:       "empty value resets to default",
+			args:       []string{"--branch", ""},
+			wantsValue: "main",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var branch string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			cmd.Flags().StringVar(&branch, "branch", "main", "the branch")
+			CoerceEmptyToDefault(cmd.Flags().Lookup("branch"))
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, branch)
+		})
+	}
+}
+
+func TestExplicitBoolFlag(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		wants ExplicitBool
+	}{
+		{
+			name:  "not passed",
+			args:  []string{},
+			wants: ExplicitBool{Value: false, Set: false},
+		},
+		{
+			name:  "passed without value",
+			args:  []string{"--draft"},
+			wants: ExplicitBool{Value: true, Set: true},
+		},
+		{
+			name:  "explicitly false",
+			args:  []string{"--draft=false"},
+			wants: ExplicitBool{Value: false, Set: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var draft ExplicitBool
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			ExplicitBoolFlag(cmd, &draft, "draft", "", "mark as draft")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wants, draft)
+		})
+	}
+}
+
+func TestStringEnumFlagPromptWhenUnset(t *testing.T) {
+	options := []string{"us-east", "us-west", "eu-central"}
+	tests := []struct {
+		name        string
+		args        []string
+		interactive bool
+		answers     []string
+		wantsValue  string
+		wantsError  string
+	}{
+		{
+			name:        "flag given skips prompt",
+			args:        []string{"--region", "us-west"},
+			interactive: true,
+			wantsValue:  "us-west",
+		},
+		{
+			name:        "prompts when unset",
+			args:        []string{},
+			interactive: true,
+			answers:     []string{"eu-central"},
+			wantsValue:  "eu-central",
+		},
+		{
+			name:        "re-prompts until valid",
+			args:        []string{},
+			interactive: true,
+			answers:     []string{"mars", "us-east"},
+			wantsValue:  "us-east",
+		},
+		{
+			name:        "errors in non-interactive mode",
+			args:        []string{},
+			interactive: false,
+			wantsError:  "--region required when not running interactively",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var region string
+			answers := tt.answers
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringEnumFlag(cmd, &region, "region", "", "", options, "the region", EnumOpts{
+				PromptWhenUnset: true,
+				Interactive:     func() bool { return tt.interactive },
+				Prompter: func(message string, opts []string) (string, error) {
+					if len(answers) == 0 {
+						return "", errors.New("prompt aborted")
+					}
+					answer := answers[0]
+					answers = answers[1:]
+					return answer, nil
+				},
+			})
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, region)
+		})
+	}
+}
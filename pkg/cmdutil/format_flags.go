@@ -0,0 +1,459 @@
+package cmdutil
+
+import (
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/text/language"
+)
+
+// GitRefFlag defines a new string flag that only accepts syntactically valid git refs,
+// e.g. branch names, tag names, or commit SHAs. The raw ref is stored unmodified.
+func GitRefFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&gitRefValue{string: p}, name, shorthand, usage)
+}
+
+// ImageRef is a container image reference broken into its components. Registry, Tag,
+// and Digest may be empty when the reference does not specify them.
+type ImageRef struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// String reassembles the reference from its components.
+func (r ImageRef) String() string {
+	s := r.Repository
+	if r.Registry != "" {
+		s = r.Registry + "/" + s
+	}
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// ImageRefFlag defines a new flag that accepts a container image reference such as
+// "nginx:1.25" or "ghcr.io/owner/app@sha256:...", validating it against the OCI
+// reference grammar and storing the parsed components. The registry and repository are
+// lowercased; commands frequently need the components, which a regexp can't provide.
+func ImageRefFlag(cmd *cobra.Command, p *ImageRef, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&imageRefValue{ref: p}, name, shorthand, usage)
+}
+
+var (
+	imageRepositoryRE = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|[-]+)[a-z0-9]+)*)*$`)
+	imageTagRE        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
+	imageDigestRE     = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[0-9a-fA-F]{32,}$`)
+)
+
+type imageRefValue struct {
+	ref *ImageRef
+}
+
+func (v *imageRefValue) Set(value string) error {
+	var ref ImageRef
+	rest := value
+	if i := strings.Index(rest, "@"); i >= 0 {
+		ref.Digest = rest[i+1:]
+		rest = rest[:i]
+		if !imageDigestRE.MatchString(ref.Digest) {
+			return fmt.Errorf("invalid image reference %q: malformed digest", value)
+		}
+	}
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.Contains(rest[i+1:], "/") {
+		ref.Tag = rest[i+1:]
+		rest = rest[:i]
+		if !imageTagRE.MatchString(ref.Tag) {
+			return fmt.Errorf("invalid image reference %q: malformed tag", value)
+		}
+	}
+	// the first path component is a registry when it looks like a hostname
+	if i := strings.Index(rest, "/"); i >= 0 {
+		if first := rest[:i]; strings.ContainsAny(first, ".:") || first == "localhost" {
+			ref.Registry = strings.ToLower(first)
+			rest = rest[i+1:]
+		}
+	}
+	ref.Repository = strings.ToLower(rest)
+	if !imageRepositoryRE.MatchString(ref.Repository) {
+		return fmt.Errorf("invalid image reference %q: malformed repository", value)
+	}
+	*v.ref = ref
+	return nil
+}
+
+func (v *imageRefValue) String() string {
+	if v.ref == nil || v.ref.Repository == "" {
+		return ""
+	}
+	return v.ref.String()
+}
+
+func (v *imageRefValue) Type() string {
+	return "image"
+}
+
+// HostnameFlag defines a new string flag that accepts an RFC 1123 hostname such as
+// "example.com", storing the lowercased form. This validates the full dotted name;
+// it is distinct from a single-label or host:port flag.
+func HostnameFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&hostnameValue{string: p}, name, shorthand, usage)
+}
+
+var hostnameLabelRE = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+type hostnameValue struct {
+	string *string
+}
+
+func (v *hostnameValue) Set(value string) error {
+	hostname := strings.ToLower(value)
+	if hostname == "" {
+		return fmt.Errorf("hostname cannot be empty")
+	}
+	if len(hostname) > 253 {
+		return fmt.Errorf("invalid hostname %q: must be 253 characters or fewer", value)
+	}
+	for _, label := range strings.Split(hostname, ".") {
+		switch {
+		case label == "":
+			return fmt.Errorf("invalid hostname %q: empty label", value)
+		case len(label) > 63:
+			return fmt.Errorf("invalid hostname %q: label %q exceeds 63 characters", value, label)
+		case !hostnameLabelRE.MatchString(label):
+			return fmt.Errorf("invalid hostname %q: label %q must contain only letters, digits, and interior hyphens", value, label)
+		}
+	}
+	*v.string = hostname
+	return nil
+}
+
+func (v *hostnameValue) String() string {
+	return *v.string
+}
+
+func (v *hostnameValue) Type() string {
+	return "hostname"
+}
+
+// MIMETypeOpts customizes the behavior of MIMETypeFlag.
+type MIMETypeOpts struct {
+	// StripParameters stores only the canonical media type, dropping any parameters
+	// such as "; charset=utf-8".
+	StripParameters bool
+}
+
+var commonMIMETypes = []string{
+	"application/json", "application/octet-stream", "application/xml",
+	"image/jpeg", "image/png", "text/csv", "text/html", "text/plain",
+}
+
+// MIMETypeFlag defines a new string flag that accepts a MIME media type such as
+// "application/json", validating it with mime.ParseMediaType and storing the
+// canonicalized form. At most one MIMETypeOpts may be passed.
+func MIMETypeFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string, opts ...MIMETypeOpts) *pflag.Flag {
+	*p = defaultValue
+	val := &mimeTypeValue{string: p}
+	if len(opts) > 0 {
+		val.opts = opts[0]
+	}
+	f := cmd.Flags().VarPF(val, name, shorthand, usage)
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return commonMIMETypes, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+type mimeTypeValue struct {
+	string *string
+	opts   MIMETypeOpts
+}
+
+func (v *mimeTypeValue) Set(value string) error {
+	mediaType, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		return fmt.Errorf("invalid MIME type %q: %v", value, err)
+	}
+	if v.opts.StripParameters || len(params) == 0 {
+		*v.string = mediaType
+	} else {
+		*v.string = mime.FormatMediaType(mediaType, params)
+	}
+	return nil
+}
+
+func (v *mimeTypeValue) String() string {
+	return *v.string
+}
+
+func (v *mimeTypeValue) Type() string {
+	return "mime-type"
+}
+
+// PhoneNumberFlag defines a new string flag that accepts a phone number and stores its
+// canonical E.164 form ("+14155552671"). Punctuation and spacing are ignored; numbers
+// without a country code are resolved using the calling code of defaultRegion (an
+// ISO 3166-1 alpha-2 code such as "US"). A leading trunk zero is dropped for regions
+// whose numbering plan treats it as a dialing prefix, and kept for plans like Italy's
+// where the zero is part of the number. Every ITU-T assigned country calling code is
+// recognized, both for defaultRegion and when validating the prefix of an explicit
+// "+" number; numbers in the North American Numbering Plan are additionally checked
+// for their fixed 11-digit shape. This does not validate national significant numbers
+// beyond the E.164 length rules.
+func PhoneNumberFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue string, defaultRegion string, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&phoneNumberValue{string: p, defaultRegion: defaultRegion}, name, shorthand, usage)
+}
+
+// regionCallingCodes maps every ISO 3166-1 alpha-2 region with an ITU-T assigned
+// country calling code to that code. Regions sharing a numbering plan (the North
+// American Numbering Plan, Kazakhstan with Russia, the UK crown dependencies) map to
+// the shared code.
+var regionCallingCodes = map[string]string{
+	// zone 1: North American Numbering Plan
+	"AG": "1", "AI": "1", "AS": "1", "BB": "1", "BM": "1", "BS": "1", "CA": "1",
+	"DM": "1", "DO": "1", "GD": "1", "GU": "1", "JM": "1", "KN": "1", "KY": "1",
+	"LC": "1", "MP": "1", "MS": "1", "PR": "1", "SX": "1", "TC": "1", "TT": "1",
+	"US": "1", "VC": "1", "VG": "1", "VI": "1",
+	// zone 2: Africa and some Atlantic islands
+	"EG": "20", "SS": "211", "EH": "212", "MA": "212", "DZ": "213", "TN": "216",
+	"LY": "218", "GM": "220", "SN": "221", "MR": "222", "ML": "223", "GN": "224",
+	"CI": "225", "BF": "226", "NE": "227", "TG": "228", "BJ": "229", "MU": "230",
+	"LR": "231", "SL": "232", "GH": "233", "NG": "234", "TD": "235", "CF": "236",
+	"CM": "237", "CV": "238", "ST": "239", "GQ": "240", "GA": "241", "CG": "242",
+	"CD": "243", "AO": "244", "GW": "245", "IO": "246", "SC": "248", "SD": "249",
+	"RW": "250", "ET": "251", "SO": "252", "DJ": "253", "KE": "254", "TZ": "255",
+	"UG": "256", "BI": "257", "MZ": "258", "ZM": "260", "MG": "261", "RE": "262",
+	"YT": "262", "ZW": "263", "NA": "264", "MW": "265", "LS": "266", "BW": "267",
+	"SZ": "268", "KM": "269", "ZA": "27", "SH": "290", "ER": "291", "AW": "297",
+	"FO": "298", "GL": "299",
+	// zones 3 and 4: Europe
+	"GR": "30", "NL": "31", "BE": "32", "FR": "33", "ES": "34", "GI": "350",
+	"PT": "351", "LU": "352", "IE": "353", "IS": "354", "AL": "355", "MT": "356",
+	"CY": "357", "AX": "358", "FI": "358", "BG": "359", "HU": "36", "LT": "370",
+	"LV": "371", "EE": "372", "MD": "373", "AM": "374", "BY": "375", "AD": "376",
+	"MC": "377", "SM": "378", "UA": "380", "RS": "381", "ME": "382", "XK": "383",
+	"HR": "385", "SI": "386", "BA": "387", "MK": "389", "IT": "39", "VA": "39",
+	"RO": "40", "CH": "41", "CZ": "420", "SK": "421", "LI": "423", "AT": "43",
+	"GB": "44", "GG": "44", "IM": "44", "JE": "44", "DK": "45", "SE": "46",
+	"NO": "47", "SJ": "47", "PL": "48", "DE": "49",
+	// zone 5: Central and South America
+	"FK": "500", "GS": "500", "BZ": "501", "GT": "502", "SV": "503", "HN": "504",
+	"NI": "505", "CR": "506", "PA": "507", "PM": "508", "HT": "509", "PE": "51",
+	"MX": "52", "CU": "53", "AR": "54", "BR": "55", "CL": "56", "CO": "57",
+	"VE": "58", "BL": "590", "GP": "590", "MF": "590", "BO": "591", "GY": "592",
+	"EC": "593", "GF": "594", "PY": "595", "MQ": "596", "SR": "597", "UY": "598",
+	"BQ": "599", "CW": "599",
+	// zone 6: Southeast Asia and Oceania
+	"MY": "60", "AU": "61", "CC": "61", "CX": "61", "ID": "62", "PH": "63",
+	"NZ": "64", "PN": "64", "SG": "65", "TH": "66", "TL": "670", "AQ": "672",
+	"NF": "672", "BN": "673", "NR": "674", "PG": "675", "TO": "676", "SB": "677",
+	"VU": "678", "FJ": "679", "PW": "680", "WF": "681", "CK": "682", "NU": "683",
+	"WS": "685", "KI": "686", "NC": "687", "TV": "688", "PF": "689", "TK": "690",
+	"FM": "691", "MH": "692",
+	// zone 7: Russia and Kazakhstan
+	"KZ": "7", "RU": "7",
+	// zone 8: East Asia
+	"JP": "81", "KR": "82", "VN": "84", "KP": "850", "HK": "852", "MO": "853",
+	"KH": "855", "LA": "856", "CN": "86", "BD": "880", "TW": "886",
+	// zone 9: Middle East, South and Central Asia
+	"TR": "90", "IN": "91", "PK": "92", "AF": "93", "LK": "94", "MM": "95",
+	"MV": "960", "LB": "961", "JO": "962", "SY": "963", "IQ": "964", "KW": "965",
+	"SA": "966", "YE": "967", "OM": "968", "PS": "970", "AE": "971", "IL": "972",
+	"BH": "973", "QA": "974", "BT": "975", "MN": "976", "NP": "977", "IR": "98",
+	"TJ": "992", "TM": "993", "AZ": "994", "GE": "995", "KG": "996", "UZ": "998",
+}
+
+// globalCallingCodes are ITU-T assigned codes for non-geographic services (freephone,
+// satellite, and other global networks) that are valid in E.164 numbers but belong to
+// no region.
+var globalCallingCodes = []string{"800", "808", "870", "878", "881", "882", "883", "888", "979"}
+
+// knownCallingCodes is the set of all assigned calling codes, used to validate the
+// prefix of an explicit international number. The ITU plan is prefix-free, so matching
+// longest-first is unambiguous.
+var knownCallingCodes = func() map[string]bool {
+	codes := make(map[string]bool)
+	for _, code := range regionCallingCodes {
+		codes[code] = true
+	}
+	for _, code := range globalCallingCodes {
+		codes[code] = true
+	}
+	return codes
+}()
+
+// trunkZeroKept lists regions whose numbering plan keeps the leading zero in the
+// international form of the number, so it must not be stripped as a trunk prefix.
+var trunkZeroKept = map[string]bool{"IT": true, "VA": true}
+
+// callingCode returns the assigned calling code prefixing digits, or "" if none.
+func callingCode(digits string) string {
+	for l := 3; l >= 1; l-- {
+		if len(digits) >= l && knownCallingCodes[digits[:l]] {
+			return digits[:l]
+		}
+	}
+	return ""
+}
+
+type phoneNumberValue struct {
+	string        *string
+	defaultRegion string
+}
+
+func (v *phoneNumberValue) Set(value string) error {
+	stripped := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, value)
+	digits := stripped
+	if strings.HasPrefix(stripped, "+") {
+		digits = stripped[1:]
+	} else {
+		region := strings.ToUpper(v.defaultRegion)
+		code, ok := regionCallingCodes[region]
+		if !ok {
+			return fmt.Errorf("invalid phone number %q: no country code and unknown default region %q", value, v.defaultRegion)
+		}
+		if !trunkZeroKept[region] {
+			digits = strings.TrimPrefix(digits, "0")
+		}
+		digits = code + digits
+	}
+	if digits == "" || strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return fmt.Errorf("invalid phone number %q: must contain only digits and an optional leading \"+\"", value)
+	}
+	if len(digits) < 8 || len(digits) > 15 {
+		return fmt.Errorf("invalid phone number %q: E.164 numbers have 8 to 15 digits (got %d)", value, len(digits))
+	}
+	code := callingCode(digits)
+	if code == "" {
+		return fmt.Errorf("invalid phone number %q: unknown country calling code", value)
+	}
+	if code == "1" && (len(digits) != 11 || digits[1] < '2') {
+		return fmt.Errorf("invalid phone number %q: North American numbers have 11 digits and an area code starting with 2-9", value)
+	}
+	*v.string = "+" + digits
+	return nil
+}
+
+func (v *phoneNumberValue) String() string {
+	return *v.string
+}
+
+func (v *phoneNumberValue) Type() string {
+	return "phone"
+}
+
+// LocaleFlag defines a new string flag that accepts a BCP 47 language tag such as
+// "en-US" and stores its canonical form.
+func LocaleFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string) *pflag.Flag {
+	*p = defaultValue
+	f := cmd.Flags().VarPF(&localeValue{string: p}, name, shorthand, usage)
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return commonLocales, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+var commonLocales = []string{
+	"de-DE", "en-GB", "en-US", "es-ES", "fr-FR", "it-IT", "ja-JP", "ko-KR", "pt-BR", "zh-CN", "zh-TW",
+}
+
+type localeValue struct {
+	string *string
+}
+
+func (v *localeValue) Set(value string) error {
+	tag, err := language.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid locale %q: %v", value, err)
+	}
+	*v.string = tag.String()
+	return nil
+}
+
+func (v *localeValue) String() string {
+	return *v.string
+}
+
+func (v *localeValue) Type() string {
+	return "locale"
+}
+
+type gitRefValue struct {
+	string *string
+}
+
+func (v *gitRefValue) Set(value string) error {
+	if err := validateGitRef(value); err != nil {
+		return err
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *gitRefValue) String() string {
+	return *v.string
+}
+
+func (v *gitRefValue) Type() string {
+	return "ref"
+}
+
+// validateGitRef checks a ref against the rules of git-check-ref-format(1).
+func validateGitRef(ref string) error {
+	if ref == "" {
+		return fmt.Errorf("ref cannot be empty")
+	}
+	if ref == "@" {
+		return fmt.Errorf("invalid ref %q: a ref cannot be the single character \"@\"", ref)
+	}
+	if strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") {
+		return fmt.Errorf("invalid ref %q: a ref cannot begin or end with \"/\"", ref)
+	}
+	if strings.Contains(ref, "//") {
+		return fmt.Errorf("invalid ref %q: a ref cannot contain \"//\"", ref)
+	}
+	if strings.Contains(ref, "..") {
+		return fmt.Errorf("invalid ref %q: a ref cannot contain \"..\"", ref)
+	}
+	if strings.Contains(ref, "@{") {
+		return fmt.Errorf("invalid ref %q: a ref cannot contain \"@{\"", ref)
+	}
+	if strings.HasSuffix(ref, ".") {
+		return fmt.Errorf("invalid ref %q: a ref cannot end with \".\"", ref)
+	}
+	for _, r := range ref {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			return fmt.Errorf("invalid ref %q: a ref cannot contain control characters", ref)
+		case r == ' ', r == '~', r == '^', r == ':', r == '?', r == '*', r == '[', r == '\\':
+			return fmt.Errorf("invalid ref %q: a ref cannot contain %q", ref, r)
+		}
+	}
+	for _, component := range strings.Split(ref, "/") {
+		if strings.HasPrefix(component, ".") {
+			return fmt.Errorf("invalid ref %q: a ref component cannot begin with \".\"", ref)
+		}
+		if strings.HasSuffix(component, ".lock") {
+			return fmt.Errorf("invalid ref %q: a ref component cannot end with \".lock\"", ref)
+		}
+	}
+	return nil
+}
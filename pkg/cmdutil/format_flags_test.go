@@ -0,0 +1,384 @@
+package cmdutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageRefFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsRef   ImageRef
+		wantsError string
+	}{
+		{
+			name:     "repository with tag",
+			value:    "nginx:1.25",
+			wantsRef: ImageRef{Repository: "nginx", Tag: "1.25"},
+		},
+		{
+			name:     "registry, nested repository, and digest",
+			value:    "ghcr.io/Owner/app@sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			wantsRef: ImageRef{Registry: "ghcr.io", Repository: "owner/app", Digest: "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"},
+		},
+		{
+			name:     "registry with port",
+			value:    "localhost:5000/app:dev",
+			wantsRef: ImageRef{Registry: "localhost:5000", Repository: "app", Tag: "dev"},
+		},
+		{
+			name:       "malformed tag",
+			value:      "nginx:.bad",
+			wantsError: `invalid image reference "nginx:.bad": malformed tag`,
+		},
+		{
+			name:       "malformed digest",
+			value:      "nginx@sha256:123",
+			wantsError: `invalid image reference "nginx@sha256:123": malformed digest`,
+		},
+		{
+			name:       "empty repository",
+			value:      ":tag",
+			wantsError: `invalid image reference ":tag": malformed repository`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ref ImageRef
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			ImageRefFlag(cmd, &ref, "image", "", "the container image")
+			cmd.SetArgs([]string{"--image", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsRef, ref)
+		})
+	}
+}
+
+func TestMIMETypeFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []MIMETypeOpts
+		value      string
+		wantsValue string
+		wantsError string
+	}{
+		{
+			name:       "plain type",
+			value:      "application/json",
+			wantsValue: "application/json",
+		},
+		{
+			name:       "canonicalizes casing and keeps parameters",
+			value:      "Text/HTML; Charset=UTF-8",
+			wantsValue: "text/html; charset=UTF-8",
+		},
+		{
+			name:       "parameters stripped on request",
+			opts:       []MIMETypeOpts{{StripParameters: true}},
+			value:      "text/html; charset=utf-8",
+			wantsValue: "text/html",
+		},
+		{
+			name:       "malformed type",
+			value:      "not a type",
+			wantsError: `invalid argument "not a type" for "--content-type" flag: invalid MIME type "not a type": mime: expected slash after first token`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var contentType string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			MIMETypeFlag(cmd, &contentType, "content-type", "", "", "the content type", tt.opts...)
+			cmd.SetArgs([]string{"--content-type", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, contentType)
+		})
+	}
+}
+
+func TestLocaleFlag(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantsLocale string
+		wantsError  string
+	}{
+		{
+			name:        "no flag",
+			args:        []string{},
+			wantsLocale: "en-US",
+		},
+		{
+			name:        "canonicalizes casing",
+			args:        []string{"--locale", "pt-br"},
+			wantsLocale: "pt-BR",
+		},
+		{
+			name:       "malformed tag",
+			args:       []string{"--locale", "?!"},
+			wantsError: `invalid argument "?!" for "--locale" flag: invalid locale "?!": language: tag is not well-formed`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var locale string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			LocaleFlag(cmd, &locale, "locale", "", "en-US", "the display locale")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsLocale, locale)
+		})
+	}
+}
+
+func TestGitRefFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantsRef   string
+		wantsError string
+	}{
+		{
+			name:     "no flag",
+			args:     []string{},
+			wantsRef: "main",
+		},
+		{
+			name:     "branch name",
+			args:     []string{"--ref", "feature/trunk"},
+			wantsRef: "feature/trunk",
+		},
+		{
+			name:     "commit SHA",
+			args:     []string{"--ref", "0cf1c8ac24c8ce9ee12cc297f348856ae9fbfbdb"},
+			wantsRef: "0cf1c8ac24c8ce9ee12cc297f348856ae9fbfbdb",
+		},
+		{
+			name:       "double dot",
+			args:       []string{"--ref", "main..other"},
+			wantsError: `invalid argument "main..other" for "--ref" flag: invalid ref "main..other": a ref cannot contain ".."`,
+		},
+		{
+			name:       "leading slash",
+			args:       []string{"--ref", "/main"},
+			wantsError: `invalid argument "/main" for "--ref" flag: invalid ref "/main": a ref cannot begin or end with "/"`,
+		},
+		{
+			name:       "component starts with dot",
+			args:       []string{"--ref", "heads/.hidden"},
+			wantsError: `invalid argument "heads/.hidden" for "--ref" flag: invalid ref "heads/.hidden": a ref component cannot begin with "."`,
+		},
+		{
+			name:       "contains space",
+			args:       []string{"--ref", "my branch"},
+			wantsError: `invalid argument "my branch" for "--ref" flag: invalid ref "my branch": a ref cannot contain ' '`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ref string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			GitRefFlag(cmd, &ref, "ref", "", "main", "the git ref")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsRef, ref)
+		})
+	}
+}
+
+func TestHostnameFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsValue string
+		wantsError string
+	}{
+		{
+			name:       "simple hostname",
+			value:      "example.com",
+			wantsValue: "example.com",
+		},
+		{
+			name:       "lowercased",
+			value:      "API.Example.COM",
+			wantsValue: "api.example.com",
+		},
+		{
+			name:       "single label",
+			value:      "localhost",
+			wantsValue: "localhost",
+		},
+		{
+			name:       "trailing dot",
+			value:      "example.com.",
+			wantsError: `invalid argument "example.com." for "--host" flag: invalid hostname "example.com.": empty label`,
+		},
+		{
+			name:       "leading hyphen in label",
+			value:      "-bad.example.com",
+			wantsError: `invalid argument "-bad.example.com" for "--host" flag: invalid hostname "-bad.example.com": label "-bad" must contain only letters, digits, and interior hyphens`,
+		},
+		{
+			name:       "underscore rejected",
+			value:      "my_host.example.com",
+			wantsError: `invalid argument "my_host.example.com" for "--host" flag: invalid hostname "my_host.example.com": label "my_host" must contain only letters, digits, and interior hyphens`,
+		},
+		{
+			name:       "label too long",
+			value:      strings.Repeat("a", 64) + ".example.com",
+			wantsError: fmt.Sprintf(`invalid argument %[1]q for "--host" flag: invalid hostname %[1]q: label %[2]q exceeds 63 characters`, strings.Repeat("a", 64)+".example.com", strings.Repeat("a", 64)),
+		},
+		{
+			name:       "total too long",
+			value:      strings.Repeat("a.", 127) + "com",
+			wantsError: fmt.Sprintf(`invalid argument %[1]q for "--host" flag: invalid hostname %[1]q: must be 253 characters or fewer`, strings.Repeat("a.", 127)+"com"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var host string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			HostnameFlag(cmd, &host, "host", "", "", "the hostname")
+			cmd.SetArgs([]string{"--host", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, host)
+		})
+	}
+}
+
+func TestPhoneNumberFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		region     string
+		value      string
+		wantsValue string
+		wantsError string
+	}{
+		{
+			name:       "already E.164",
+			region:     "US",
+			value:      "+14155552671",
+			wantsValue: "+14155552671",
+		},
+		{
+			name:       "formatting stripped",
+			region:     "US",
+			value:      "+1 (415) 555-2671",
+			wantsValue: "+14155552671",
+		},
+		{
+			name:       "national number uses default region",
+			region:     "US",
+			value:      "415-555-2671",
+			wantsValue: "+14155552671",
+		},
+		{
+			name:       "national trunk zero dropped",
+			region:     "GB",
+			value:      "020 7946 0958",
+			wantsValue: "+442079460958",
+		},
+		{
+			name:       "region outside the starter set",
+			region:     "SE",
+			value:      "070-123 45 67",
+			wantsValue: "+46701234567",
+		},
+		{
+			name:       "trunk zero kept for Italian numbers",
+			region:     "IT",
+			value:      "06 6982 1234",
+			wantsValue: "+390669821234",
+		},
+		{
+			name:       "unknown calling code rejected",
+			region:     "US",
+			value:      "+99912345678",
+			wantsError: `invalid argument "+99912345678" for "--phone" flag: invalid phone number "+99912345678": unknown country calling code`,
+		},
+		{
+			name:       "malformed North American number rejected",
+			region:     "US",
+			value:      "+111111111",
+			wantsError: `invalid argument "+111111111" for "--phone" flag: invalid phone number "+111111111": North American numbers have 11 digits and an area code starting with 2-9`,
+		},
+		{
+			name:       "letters rejected",
+			region:     "US",
+			value:      "+1-800-FLOWERS",
+			wantsError: `invalid argument "+1-800-FLOWERS" for "--phone" flag: invalid phone number "+1-800-FLOWERS": must contain only digits and an optional leading "+"`,
+		},
+		{
+			name:       "too short",
+			region:     "US",
+			value:      "+1415",
+			wantsError: `invalid argument "+1415" for "--phone" flag: invalid phone number "+1415": E.164 numbers have 8 to 15 digits (got 4)`,
+		},
+		{
+			name:       "unknown default region",
+			region:     "ZZ",
+			value:      "4155552671",
+			wantsError: `invalid argument "4155552671" for "--phone" flag: invalid phone number "4155552671": no country code and unknown default region "ZZ"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var phone string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			PhoneNumberFlag(cmd, &phone, "phone", "", "", tt.region, "the phone number")
+			cmd.SetArgs([]string{"--phone", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, phone)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package cmdutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// LatLng holds a geographic coordinate parsed by LatLngFlag.
+type LatLng struct {
+	Lat float64
+	Lng float64
+}
+
+// LatLngFlag defines a new flag accepting a geographic coordinate as "lat,lng", e.g.
+// "37.7749,-122.4194", validating latitude within [-90, 90] and longitude within
+// [-180, 180].
+func LatLngFlag(cmd *cobra.Command, p *LatLng, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&decodedValue[LatLng]{value: p, typeName: "lat,lng", decode: parseLatLng}, name, shorthand, usage)
+}
+
+func parseLatLng(value string) (LatLng, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return LatLng{}, fmt.Errorf("expected \"lat,lng\", got %q", value)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return LatLng{}, fmt.Errorf("invalid latitude %q", parts[0])
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return LatLng{}, fmt.Errorf("invalid longitude %q", parts[1])
+	}
+	if lat < -90 || lat > 90 {
+		return LatLng{}, fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if lng < -180 || lng > 180 {
+		return LatLng{}, fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return LatLng{Lat: lat, Lng: lng}, nil
+}
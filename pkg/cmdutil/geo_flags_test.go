@@ -0,0 +1,67 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatLngFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wants   LatLng
+		wantErr string
+	}{
+		{
+			name:  "valid coordinate",
+			args:  []string{"--location", "37.7749,-122.4194"},
+			wants: LatLng{Lat: 37.7749, Lng: -122.4194},
+		},
+		{
+			name:  "spaces around components",
+			args:  []string{"--location", "37.7749, -122.4194"},
+			wants: LatLng{Lat: 37.7749, Lng: -122.4194},
+		},
+		{
+			name:    "missing longitude",
+			args:    []string{"--location", "37.7749"},
+			wantErr: `invalid argument "37.7749" for "--location" flag: expected "lat,lng", got "37.7749"`,
+		},
+		{
+			name:    "malformed latitude",
+			args:    []string{"--location", "north,-122"},
+			wantErr: `invalid argument "north,-122" for "--location" flag: invalid latitude "north"`,
+		},
+		{
+			name:    "latitude out of range",
+			args:    []string{"--location", "91,-122"},
+			wantErr: `invalid argument "91,-122" for "--location" flag: latitude must be between -90 and 90`,
+		},
+		{
+			name:    "longitude out of range",
+			args:    []string{"--location", "37,-181"},
+			wantErr: `invalid argument "37,-181" for "--location" flag: longitude must be between -180 and 180`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var location LatLng
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			LatLngFlag(cmd, &location, "location", "", "coordinate to search near")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wants, location)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package cmdutil
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// crockfordAlphabet is the Crockford base32 alphabet used by ULIDs, which omits
+// I, L, O, and U to avoid transcription ambiguity.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDFlag defines a new string flag that accepts a ULID (26 characters of Crockford
+// base32) and stores the canonical uppercase form. Use ULIDTime to extract the
+// timestamp component of the stored value.
+func ULIDFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&ulidValue{string: p}, name, shorthand, usage)
+}
+
+type ulidValue struct {
+	string *string
+}
+
+func (v *ulidValue) Set(value string) error {
+	canonical := strings.ToUpper(value)
+	if len(canonical) != 26 {
+		return fmt.Errorf("invalid ULID")
+	}
+	for _, c := range canonical {
+		if !strings.ContainsRune(crockfordAlphabet, c) {
+			return fmt.Errorf("invalid ULID")
+		}
+	}
+	// the 48-bit timestamp occupies the first 10 characters; the leading character
+	// carries only 3 bits, so anything above "7" overflows
+	if canonical[0] > '7' {
+		return fmt.Errorf("invalid ULID")
+	}
+	*v.string = canonical
+	return nil
+}
+
+func (v *ulidValue) String() string {
+	return *v.string
+}
+
+func (v *ulidValue) Type() string {
+	return "ulid"
+}
+
+// ULIDTime extracts the millisecond timestamp encoded in the first 10 characters of a
+// canonical ULID.
+func ULIDTime(ulid string) (time.Time, error) {
+	if len(ulid) != 26 {
+		return time.Time{}, fmt.Errorf("invalid ULID")
+	}
+	var ms uint64
+	for _, c := range ulid[:10] {
+		i := strings.IndexRune(crockfordAlphabet, c)
+		if i < 0 {
+			return time.Time{}, fmt.Errorf("invalid ULID")
+		}
+		ms = ms<<5 | uint64(i)
+	}
+	return time.Unix(0, int64(ms)*int64(time.Millisecond)).UTC(), nil
+}
+
+// ksuidAlphabet is the base62 alphabet used by KSUIDs.
+const ksuidAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// KSUIDFlag defines a new string flag that accepts a KSUID (27 characters of base62).
+// Unlike ULIDs the encoding is case-sensitive, so the value is stored as given. Use
+// KSUIDTime to extract the timestamp component.
+func KSUIDFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&ksuidValue{string: p}, name, shorthand, usage)
+}
+
+type ksuidValue struct {
+	string *string
+}
+
+func (v *ksuidValue) Set(value string) error {
+	if _, err := decodeKSUID(value); err != nil {
+		return err
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *ksuidValue) String() string {
+	return *v.string
+}
+
+func (v *ksuidValue) Type() string {
+	return "ksuid"
+}
+
+// KSUIDTime extracts the timestamp component of a KSUID, which counts seconds from
+// the KSUID epoch (2014-05-13T16:53:20Z).
+func KSUIDTime(ksuid string) (time.Time, error) {
+	raw, err := decodeKSUID(ksuid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds := uint64(raw[0])<<24 | uint64(raw[1])<<16 | uint64(raw[2])<<8 | uint64(raw[3])
+	const ksuidEpoch = 1400000000
+	return time.Unix(int64(seconds)+ksuidEpoch, 0).UTC(), nil
+}
+
+// decodeKSUID decodes a 27-character base62 KSUID into its 20 raw bytes.
+func decodeKSUID(value string) ([]byte, error) {
+	if len(value) != 27 {
+		return nil, fmt.Errorf("invalid KSUID")
+	}
+	raw := make([]byte, 20)
+	for _, c := range value {
+		digit := strings.IndexRune(ksuidAlphabet, c)
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid KSUID")
+		}
+		carry := digit
+		for i := len(raw) - 1; i >= 0; i-- {
+			carry += int(raw[i]) * 62
+			raw[i] = byte(carry & 0xff)
+			carry >>= 8
+		}
+		if carry > 0 {
+			return nil, fmt.Errorf("invalid KSUID")
+		}
+	}
+	return raw, nil
+}
@@ -0,0 +1,120 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestULIDFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsValue string
+		wantsError string
+	}{
+		{
+			name:       "canonical ULID",
+			value:      "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+			wantsValue: "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		},
+		{
+			name:       "lowercase canonicalized",
+			value:      "01arz3ndektsv4rrffq69g5fav",
+			wantsValue: "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+		},
+		{
+			name:       "wrong length",
+			value:      "01ARZ3NDEK",
+			wantsError: `invalid argument "01ARZ3NDEK" for "--id" flag: invalid ULID`,
+		},
+		{
+			name:       "excluded letter",
+			value:      "01ARZ3NDEKTSV4RRFFQ69G5FIL",
+			wantsError: `invalid argument "01ARZ3NDEKTSV4RRFFQ69G5FIL" for "--id" flag: invalid ULID`,
+		},
+		{
+			name:       "timestamp overflow",
+			value:      "8ZZZZZZZZZZZZZZZZZZZZZZZZZ",
+			wantsError: `invalid argument "8ZZZZZZZZZZZZZZZZZZZZZZZZZ" for "--id" flag: invalid ULID`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			ULIDFlag(cmd, &id, "id", "", "", "the record ID")
+			cmd.SetArgs([]string{"--id", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, id)
+		})
+	}
+}
+
+func TestULIDTime(t *testing.T) {
+	ts, err := ULIDTime("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 7, 30, 23, 54, 10, 259000000, time.UTC), ts)
+}
+
+func TestKSUIDFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsError string
+	}{
+		{
+			name:  "valid KSUID",
+			value: "0ujtsYcgvSTl8PAuAdqWYSMnLOv",
+		},
+		{
+			name:       "wrong length",
+			value:      "0ujtsYcgvSTl8PAuAdqWYSMnLO",
+			wantsError: `invalid argument "0ujtsYcgvSTl8PAuAdqWYSMnLO" for "--id" flag: invalid KSUID`,
+		},
+		{
+			name:       "invalid character",
+			value:      "0ujtsYcgvSTl8PAuAdqWYSMnL-v",
+			wantsError: `invalid argument "0ujtsYcgvSTl8PAuAdqWYSMnL-v" for "--id" flag: invalid KSUID`,
+		},
+		{
+			name:       "overflows 20 bytes",
+			value:      "zzzzzzzzzzzzzzzzzzzzzzzzzzz",
+			wantsError: `invalid argument "zzzzzzzzzzzzzzzzzzzzzzzzzzz" for "--id" flag: invalid KSUID`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			KSUIDFlag(cmd, &id, "id", "", "", "the record ID")
+			cmd.SetArgs([]string{"--id", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, id)
+		})
+	}
+}
+
+func TestKSUIDTime(t *testing.T) {
+	ts, err := KSUIDTime("0ujtsYcgvSTl8PAuAdqWYSMnLOv")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 10, 10, 4, 0, 47, 0, time.UTC), ts)
+}
@@ -0,0 +1,277 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// JSONSchemaOpts customizes the behavior of JSONSchemaFlag.
+type JSONSchemaOpts struct {
+	// SchemaFile names a local file holding the JSON schema.
+	SchemaFile string
+	// SchemaURL locates a centrally-hosted JSON schema; it is fetched at most once,
+	// on the first use of the flag, and cached for subsequent uses.
+	SchemaURL string
+	// Client performs the SchemaURL fetch; defaults to http.DefaultClient. Tests
+	// inject a stub.
+	Client *http.Client
+}
+
+// SchemaFetchError reports a failure to load the schema itself, as distinct from
+// input that fails validation against a successfully loaded schema.
+type SchemaFetchError struct {
+	err error
+}
+
+func (e *SchemaFetchError) Error() string {
+	return fmt.Sprintf("could not load schema: %v", e.err)
+}
+
+func (e *SchemaFetchError) Unwrap() error {
+	return e.err
+}
+
+// JSONSchemaFlag defines a new flag that accepts a JSON document, either inline or
+// from a file with "@", and validates it against a JSON schema loaded from a file or
+// URL. The schema supports a practical subset of JSON Schema: "type", "properties",
+// "required", "items", and "enum". Schemas using any other constraint keyword are
+// rejected at load time rather than silently half-enforced. Schema-load failures
+// surface as a SchemaFetchError so callers can distinguish them from invalid input.
+func JSONSchemaFlag(cmd *cobra.Command, p *json.RawMessage, name, shorthand string, opts JSONSchemaOpts, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&jsonSchemaValue{raw: p, opts: opts}, name, shorthand, usage)
+}
+
+type jsonSchemaValue struct {
+	raw    *json.RawMessage
+	opts   JSONSchemaOpts
+	schema map[string]interface{}
+}
+
+func (v *jsonSchemaValue) Set(value string) error {
+	if v.schema == nil {
+		schema, err := v.loadSchema()
+		if err != nil {
+			return &SchemaFetchError{err: err}
+		}
+		v.schema = schema
+	}
+	data := []byte(value)
+	if strings.HasPrefix(value, "@") {
+		var err error
+		data, err = readFileValue(value[1:], 0)
+		if err != nil {
+			return err
+		}
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := validateJSONSchema(v.schema, doc, ""); err != nil {
+		return err
+	}
+	*v.raw = json.RawMessage(data)
+	return nil
+}
+
+// loadSchema reads the schema document from the configured file or URL.
+func (v *jsonSchemaValue) loadSchema() (map[string]interface{}, error) {
+	var data []byte
+	switch {
+	case v.opts.SchemaFile != "":
+		var err error
+		data, err = ioutil.ReadFile(v.opts.SchemaFile)
+		if err != nil {
+			return nil, err
+		}
+	case v.opts.SchemaURL != "":
+		client := v.opts.Client
+		if client == nil {
+			client = http.DefaultClient
+		}
+		resp, err := client.Get(v.opts.SchemaURL)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, v.opts.SchemaURL)
+		}
+		data, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("no schema source configured")
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema document: %v", err)
+	}
+	if err := checkSchemaKeywords(schema, ""); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// supportedSchemaKeywords are the constraint keywords validateJSONSchema enforces,
+// plus annotation keywords that carry no constraints and are safe to ignore.
+var supportedSchemaKeywords = map[string]bool{
+	"type": true, "enum": true, "required": true, "properties": true, "items": true,
+	"$schema": true, "$id": true, "title": true, "description": true,
+	"default": true, "examples": true,
+}
+
+// checkSchemaKeywords rejects schemas using constraint keywords validateJSONSchema
+// does not enforce (e.g. "minimum", "$ref"), so an unsupported schema fails loudly at
+// load time instead of silently passing invalid input. Supported keywords are also
+// checked for the one form the validator handles: "type" must be a single type name,
+// not a list, and "items" must be a single schema object, not the tuple form.
+func checkSchemaKeywords(schema map[string]interface{}, path string) error {
+	at := func(keyword string) string {
+		if path == "" {
+			return keyword
+		}
+		return path + "." + keyword
+	}
+	for keyword := range schema {
+		if !supportedSchemaKeywords[keyword] {
+			return fmt.Errorf("unsupported schema keyword %q", at(keyword))
+		}
+	}
+	if t, ok := schema["type"]; ok {
+		if _, isString := t.(string); !isString {
+			return fmt.Errorf("unsupported form for schema keyword %q: must be a single type name", at("type"))
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		if _, isObject := items.(map[string]interface{}); !isObject {
+			return fmt.Errorf("unsupported form for schema keyword %q: must be a single schema object", at("items"))
+		}
+	}
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for name, sub := range properties {
+			subSchema, ok := sub.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if err := checkSchemaKeywords(subSchema, joinSchemaPath(path, name)); err != nil {
+				return err
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		if err := checkSchemaKeywords(items, path+"[]"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateJSONSchema validates a decoded JSON value against a schema subset. path
+// locates the value being validated for error messages; "" means the root.
+func validateJSONSchema(schema map[string]interface{}, value interface{}, path string) error {
+	at := func() string {
+		if path == "" {
+			return "value"
+		}
+		return fmt.Sprintf("property %q", path)
+	}
+	if want, ok := schema["type"].(string); ok {
+		if got := jsonTypeName(value); got != want && !(want == "number" && got == "integer") {
+			return fmt.Errorf("%s: expected %s, got %s", at(), want, got)
+		}
+	}
+	if options, ok := schema["enum"].([]interface{}); ok {
+		matched := false
+		for _, option := range options {
+			if fmt.Sprintf("%v", option) == fmt.Sprintf("%v", value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: %v is not one of the allowed values", at(), value)
+		}
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, field := range required {
+				name, _ := field.(string)
+				if _, present := object[name]; !present {
+					return fmt.Errorf("missing required property %q", joinSchemaPath(path, name))
+				}
+			}
+		}
+		if properties, ok := schema["properties"].(map[string]interface{}); ok {
+			for name, sub := range properties {
+				subSchema, ok := sub.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if propValue, present := object[name]; present {
+					if err := validateJSONSchema(subSchema, propValue, joinSchemaPath(path, name)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	if array, ok := value.([]interface{}); ok {
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, element := range array {
+				if err := validateJSONSchema(items, element, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// jsonTypeName reports the JSON Schema type name of a decoded JSON value.
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return "unknown"
+	}
+}
+
+func (v *jsonSchemaValue) String() string {
+	if v.raw == nil || *v.raw == nil {
+		return ""
+	}
+	return string(*v.raw)
+}
+
+func (v *jsonSchemaValue) Type() string {
+	return "json"
+}
@@ -0,0 +1,142 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchemaFlag(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string"},
+			"replicas": {"type": "integer"},
+			"tier": {"enum": ["free", "pro"]}
+		}
+	}`
+	schemaFile := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, ioutil.WriteFile(schemaFile, []byte(schema), 0600))
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{
+			name:  "conforming document",
+			value: `{"name":"api","replicas":3,"tier":"pro"}`,
+		},
+		{
+			name:    "missing required property",
+			value:   `{"replicas":3}`,
+			wantErr: `invalid argument "{\"replicas\":3}" for "--config" flag: missing required property "name"`,
+		},
+		{
+			name:    "wrong property type",
+			value:   `{"name":"api","replicas":"three"}`,
+			wantErr: `invalid argument "{\"name\":\"api\",\"replicas\":\"three\"}" for "--config" flag: property "replicas": expected integer, got string`,
+		},
+		{
+			name:    "enum violation",
+			value:   `{"name":"api","tier":"platinum"}`,
+			wantErr: `invalid argument "{\"name\":\"api\",\"tier\":\"platinum\"}" for "--config" flag: property "tier": platinum is not one of the allowed values`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw json.RawMessage
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			JSONSchemaFlag(cmd, &raw, "config", "", JSONSchemaOpts{SchemaFile: schemaFile}, "the config document")
+			cmd.SetArgs([]string{"--config", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, string(raw))
+		})
+	}
+}
+
+func TestJSONSchemaFlagFromURL(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write([]byte(`{"type":"array","items":{"type":"integer"}}`))
+	}))
+	defer server.Close()
+
+	var raw json.RawMessage
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	f := JSONSchemaFlag(cmd, &raw, "config", "", JSONSchemaOpts{SchemaURL: server.URL, Client: server.Client()}, "the config document")
+	require.NoError(t, f.Value.Set(`[1,2,3]`))
+	require.NoError(t, f.Value.Set(`[4]`))
+	assert.Equal(t, 1, fetches, "schema should be fetched once and cached")
+	assert.EqualError(t, f.Value.Set(`[1,"x"]`), `property "[1]": expected integer, got string`)
+}
+
+func TestJSONSchemaFlagUnsupportedKeyword(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{
+			name:    "unenforced keyword",
+			schema:  `{"type": "object", "properties": {"replicas": {"type": "integer", "minimum": 1}}}`,
+			wantErr: `could not load schema: unsupported schema keyword "replicas.minimum"`,
+		},
+		{
+			name:    "type list form",
+			schema:  `{"type": "object", "properties": {"name": {"type": ["string", "null"]}}}`,
+			wantErr: `could not load schema: unsupported form for schema keyword "name.type": must be a single type name`,
+		},
+		{
+			name:    "items tuple form",
+			schema:  `{"type": "array", "items": [{"type": "string"}, {"type": "integer"}]}`,
+			wantErr: `could not load schema: unsupported form for schema keyword "items": must be a single schema object`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemaFile := filepath.Join(t.TempDir(), "schema.json")
+			require.NoError(t, ioutil.WriteFile(schemaFile, []byte(tt.schema), 0600))
+
+			var raw json.RawMessage
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			f := JSONSchemaFlag(cmd, &raw, "config", "", JSONSchemaOpts{SchemaFile: schemaFile}, "the config document")
+			err := f.Value.Set(`{}`)
+			var fetchErr *SchemaFetchError
+			require.True(t, errors.As(err, &fetchErr), "expected a SchemaFetchError, got %v", err)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestJSONSchemaFlagFetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var raw json.RawMessage
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	f := JSONSchemaFlag(cmd, &raw, "config", "", JSONSchemaOpts{SchemaURL: server.URL, Client: server.Client()}, "the config document")
+	err := f.Value.Set(`{}`)
+	var fetchErr *SchemaFetchError
+	require.True(t, errors.As(err, &fetchErr), "expected a SchemaFetchError, got %v", err)
+	assert.Contains(t, err.Error(), "could not load schema")
+}
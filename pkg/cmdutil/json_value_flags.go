@@ -0,0 +1,210 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// JSONFlagOpts customizes the behavior of JSONFlag.
+type JSONFlagOpts struct {
+	// Kind, when set to "object" or "array", requires the top-level JSON value to be
+	// of that kind. Any valid JSON is accepted when blank.
+	Kind string
+	// MaxBytes caps how much an "@file" value may read; zero means no cap.
+	MaxBytes int64
+}
+
+// readFileValue reads the file backing an "@file" flag value, where path "-" reads
+// stdin. Regular files larger than maxBytes are rejected before reading; non-regular
+// files such as the named pipes produced by process substitution cannot be sized up
+// front, so they are read through an io.LimitReader and rejected only when they
+// overflow the cap. A maxBytes of zero means no cap.
+func readFileValue(path string, maxBytes int64) ([]byte, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() && maxBytes > 0 && info.Size() > maxBytes {
+			return nil, fmt.Errorf("file %s is larger than the maximum of %d bytes", path, maxBytes)
+		}
+		r = f
+	}
+	if maxBytes <= 0 {
+		return ioutil.ReadAll(r)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("input is larger than the maximum of %d bytes", maxBytes)
+	}
+	return data, nil
+}
+
+// JSONFlag defines a new flag that accepts a JSON document, either inline or from a
+// file when the value is prefixed with "@". The raw document is stored unmodified.
+func JSONFlag(cmd *cobra.Command, p *json.RawMessage, name, shorthand string, opts JSONFlagOpts, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&jsonValue{raw: p, opts: opts}, name, shorthand, usage)
+}
+
+// InlineJSONMapFlag defines a new flag that accepts an inline JSON object and stores it
+// as a map, preserving JSON types. Repeated uses merge into the same map with later
+// keys winning; non-object documents are rejected.
+func InlineJSONMapFlag(cmd *cobra.Command, p *map[string]interface{}, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&jsonMapValue{m: p}, name, shorthand, usage)
+}
+
+type jsonMapValue struct {
+	m *map[string]interface{}
+}
+
+func (v *jsonMapValue) Set(value string) error {
+	var raw json.RawMessage
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if kind := jsonKind(raw); kind != "object" {
+		return fmt.Errorf("expected a JSON object, got %s", kind)
+	}
+	var entries map[string]interface{}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if *v.m == nil {
+		*v.m = map[string]interface{}{}
+	}
+	for key, val := range entries {
+		(*v.m)[key] = val
+	}
+	return nil
+}
+
+func (v *jsonMapValue) String() string {
+	if v.m == nil || *v.m == nil {
+		return ""
+	}
+	data, err := json.Marshal(*v.m)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func (v *jsonMapValue) Type() string {
+	return "json"
+}
+
+// JSONLinesFlag defines a new flag that accepts newline-delimited JSON (one document
+// per line), either inline or from a file with "@file" ("@-" reads stdin). Each
+// non-empty line must be valid JSON; errors report the offending line number.
+func JSONLinesFlag(cmd *cobra.Command, p *[]json.RawMessage, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&jsonLinesValue{messages: p}, name, shorthand, usage)
+}
+
+type jsonLinesValue struct {
+	messages *[]json.RawMessage
+}
+
+func (v *jsonLinesValue) Set(value string) error {
+	data := []byte(value)
+	if strings.HasPrefix(value, "@") {
+		var err error
+		data, err = readFileValue(value[1:], 0)
+		if err != nil {
+			return err
+		}
+	}
+	var messages []json.RawMessage
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var raw json.RawMessage
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return fmt.Errorf("invalid JSON on line %d: %v", i+1, err)
+		}
+		messages = append(messages, raw)
+	}
+	*v.messages = append(*v.messages, messages...)
+	return nil
+}
+
+func (v *jsonLinesValue) String() string {
+	return ""
+}
+
+func (v *jsonLinesValue) Type() string {
+	return "json-lines"
+}
+
+type jsonValue struct {
+	raw  *json.RawMessage
+	opts JSONFlagOpts
+}
+
+func (v *jsonValue) Set(value string) error {
+	data := []byte(value)
+	if strings.HasPrefix(value, "@") {
+		var err error
+		data, err = readFileValue(value[1:], v.opts.MaxBytes)
+		if err != nil {
+			return err
+		}
+	}
+	var raw json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if v.opts.Kind != "" {
+		if kind := jsonKind(raw); kind != v.opts.Kind {
+			return fmt.Errorf("expected a JSON %s, got %s", v.opts.Kind, kind)
+		}
+	}
+	*v.raw = raw
+	return nil
+}
+
+// jsonKind reports the kind of the top-level value of a valid JSON document based on
+// its first non-whitespace byte.
+func jsonKind(data []byte) string {
+	trimmed := strings.TrimLeft(string(data), " \t\r\n")
+	if trimmed == "" {
+		return "empty"
+	}
+	switch c := trimmed[0]; {
+	case c == '{':
+		return "object"
+	case c == '[':
+		return "array"
+	case c == '"':
+		return "string"
+	case c == 't' || c == 'f':
+		return "boolean"
+	case c == 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+func (v *jsonValue) String() string {
+	if v.raw == nil || *v.raw == nil {
+		return ""
+	}
+	return string(*v.raw)
+}
+
+func (v *jsonValue) Type() string {
+	return "json"
+}
@@ -0,0 +1,213 @@
+package cmdutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       JSONFlagOpts
+		value      string
+		wantsJSON  string
+		wantsError string
+	}{
+		{
+			name:      "inline object",
+			value:     `{"a":1}`,
+			wantsJSON: `{"a":1}`,
+		},
+		{
+			name:      "object required",
+			opts:      JSONFlagOpts{Kind: "object"},
+			value:     ` {"a":1}`,
+			wantsJSON: `{"a":1}`,
+		},
+		{
+			name:       "object required, got array",
+			opts:       JSONFlagOpts{Kind: "object"},
+			value:      `[1,2]`,
+			wantsError: `invalid argument "[1,2]" for "--patch" flag: expected a JSON object, got array`,
+		},
+		{
+			name:       "array required, got scalar",
+			opts:       JSONFlagOpts{Kind: "array"},
+			value:      `42`,
+			wantsError: `invalid argument "42" for "--patch" flag: expected a JSON array, got number`,
+		},
+		{
+			name:       "invalid JSON",
+			value:      `{"a":`,
+			wantsError: `invalid argument "{\"a\":" for "--patch" flag: invalid JSON: unexpected end of JSON input`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw json.RawMessage
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			JSONFlag(cmd, &raw, "patch", "", tt.opts, "the patch document")
+			cmd.SetArgs([]string{"--patch", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsJSON, string(raw))
+		})
+	}
+}
+
+func TestInlineJSONMapFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantsMap   map[string]interface{}
+		wantsError string
+	}{
+		{
+			name:     "preserves JSON types",
+			args:     []string{"--metadata", `{"count":3,"active":true,"name":"x"}`},
+			wantsMap: map[string]interface{}{"count": float64(3), "active": true, "name": "x"},
+		},
+		{
+			name:     "repeated uses merge with later keys winning",
+			args:     []string{"--metadata", `{"a":1,"b":1}`, "--metadata", `{"b":2}`},
+			wantsMap: map[string]interface{}{"a": float64(1), "b": float64(2)},
+		},
+		{
+			name:       "non-object rejected",
+			args:       []string{"--metadata", `[1,2]`},
+			wantsError: `invalid argument "[1,2]" for "--metadata" flag: expected a JSON object, got array`,
+		},
+		{
+			name:       "invalid JSON",
+			args:       []string{"--metadata", `{`},
+			wantsError: `invalid argument "{" for "--metadata" flag: invalid JSON: unexpected end of JSON input`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var metadata map[string]interface{}
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			InlineJSONMapFlag(cmd, &metadata, "metadata", "", "structured metadata")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsMap, metadata)
+		})
+	}
+}
+
+func TestJSONFlagFromFile(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "p.json")
+	require.NoError(t, ioutil.WriteFile(f, []byte(`{"op":"add"}`), 0600))
+
+	var raw json.RawMessage
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	JSONFlag(cmd, &raw, "patch", "", JSONFlagOpts{Kind: "object"}, "the patch document")
+	cmd.SetArgs([]string{"--patch", "@" + f})
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+	_, err := cmd.ExecuteC()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"op":"add"}`, string(raw))
+}
+
+func TestJSONLinesFlag(t *testing.T) {
+	recordsFile := filepath.Join(t.TempDir(), "data.jsonl")
+	require.NoError(t, ioutil.WriteFile(recordsFile, []byte("{\"a\":1}\n\n{\"b\":2}\n"), 0600))
+
+	tests := []struct {
+		name       string
+		value      string
+		wantsDocs  []json.RawMessage
+		wantsError string
+	}{
+		{
+			name:      "inline lines with blank skipped",
+			value:     "{\"a\":1}\n\n[2,3]",
+			wantsDocs: []json.RawMessage{json.RawMessage(`{"a":1}`), json.RawMessage(`[2,3]`)},
+		},
+		{
+			name:      "from file",
+			value:     "@" + recordsFile,
+			wantsDocs: []json.RawMessage{json.RawMessage(`{"a":1}`), json.RawMessage(`{"b":2}`)},
+		},
+		{
+			name:       "reports offending line",
+			value:      "{\"a\":1}\n{oops}",
+			wantsError: `invalid argument "{\"a\":1}\n{oops}" for "--records" flag: invalid JSON on line 2: invalid character 'o' looking for beginning of object key string`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []json.RawMessage
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			JSONLinesFlag(cmd, &records, "records", "", "the records")
+			cmd.SetArgs([]string{"--records", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsDocs, records)
+		})
+	}
+}
+
+func TestReadFileValue(t *testing.T) {
+	dir := t.TempDir()
+	regular := filepath.Join(dir, "doc.json")
+	require.NoError(t, ioutil.WriteFile(regular, []byte(`{"a":1}`), 0600))
+	t.Run("regular file", func(t *testing.T) {
+		data, err := readFileValue(regular, 0)
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(data))
+	})
+
+	t.Run("regular file over cap", func(t *testing.T) {
+		_, err := readFileValue(regular, 3)
+		assert.EqualError(t, err, fmt.Sprintf("file %s is larger than the maximum of 3 bytes", regular))
+	})
+
+	t.Run("regular file under cap", func(t *testing.T) {
+		data, err := readFileValue(regular, 1024)
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(data))
+	})
+}
+
+func TestJSONFlagMaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"a":1}`), 0600))
+
+	var raw json.RawMessage
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	JSONFlag(cmd, &raw, "input", "", JSONFlagOpts{MaxBytes: 3}, "input document")
+	cmd.SetArgs([]string{"--input", "@" + path})
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+	_, err := cmd.ExecuteC()
+	assert.EqualError(t, err, fmt.Sprintf(`invalid argument "@%[1]s" for "--input" flag: file %[1]s is larger than the maximum of 3 bytes`, path))
+}
@@ -0,0 +1,44 @@
+//go:build !windows
+
+package cmdutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadFileValueFIFO exercises the size cap against pipes, whose Stat size is
+// meaningless; the cap has to be enforced while reading.
+func TestReadFileValueFIFO(t *testing.T) {
+	mkfifo := func(t *testing.T, contents string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "pipe")
+		require.NoError(t, syscall.Mkfifo(path, 0600))
+		go func() {
+			f, err := os.OpenFile(path, os.O_WRONLY, 0)
+			if err != nil {
+				return
+			}
+			defer f.Close()
+			_, _ = f.WriteString(contents)
+		}()
+		return path
+	}
+
+	t.Run("pipe under cap", func(t *testing.T) {
+		data, err := readFileValue(mkfifo(t, `{"a":1}`), 1024)
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(data))
+	})
+
+	t.Run("pipe over cap", func(t *testing.T) {
+		_, err := readFileValue(mkfifo(t, strings.Repeat("x", 32)), 16)
+		assert.EqualError(t, err, "input is larger than the maximum of 16 bytes")
+	})
+}
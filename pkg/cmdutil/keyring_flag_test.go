@@ -0,0 +1,69 @@
+package cmdutil
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeKeyring struct {
+	secrets map[string]string
+}
+
+func (k *fakeKeyring) Get(service, user string) (string, error) {
+	if secret, ok := k.secrets[service+"/"+user]; ok {
+		return secret, nil
+	}
+	return "", errors.New("secret not found")
+}
+
+func TestKeyringStringFlag(t *testing.T) {
+	keyring := &fakeKeyring{secrets: map[string]string{"myservice/myuser": "hunter2"}}
+	tests := []struct {
+		name       string
+		value      string
+		wantsValue string
+		wantsError string
+	}{
+		{
+			name:       "literal value",
+			value:      "plain-password",
+			wantsValue: "plain-password",
+		},
+		{
+			name:       "keyring lookup",
+			value:      "keyring:myservice/myuser",
+			wantsValue: "hunter2",
+		},
+		{
+			name:       "lookup failure",
+			value:      "keyring:myservice/nobody",
+			wantsError: `invalid argument "keyring:myservice/nobody" for "--password" flag: failed to read "myservice/nobody" from keyring: secret not found`,
+		},
+		{
+			name:       "malformed reference",
+			value:      "keyring:myservice",
+			wantsError: `invalid argument "keyring:myservice" for "--password" flag: invalid keyring reference "keyring:myservice": expected keyring:service/user`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var password string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			KeyringStringFlag(cmd, &password, "password", "", "the password", KeyringOpts{Backend: keyring})
+			cmd.SetArgs([]string{"--password", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, password)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package cmdutil
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// StringMapFlag defines a new flag accepting repeated "key=value" pairs collected into
+// a string map. Duplicate keys resolve last-wins, and String() emits the pairs with
+// keys sorted so serialized output is deterministic for reproducible configs and
+// golden tests. MapFlagValue returns the canonical map for a registered flag.
+func StringMapFlag(cmd *cobra.Command, p *map[string]string, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&stringMapValue{m: p}, name, shorthand, usage)
+}
+
+// MapFlagValue returns the canonical map collected by a StringMapFlag, or nil if the
+// flag is not one.
+func MapFlagValue(f *pflag.Flag) map[string]string {
+	if v, ok := f.Value.(*stringMapValue); ok {
+		return *v.m
+	}
+	return nil
+}
+
+type stringMapValue struct {
+	m *map[string]string
+}
+
+func (v *stringMapValue) Set(value string) error {
+	eq := strings.Index(value, "=")
+	if eq < 1 {
+		return fmt.Errorf("expected \"key=value\", got %q", value)
+	}
+	if *v.m == nil {
+		*v.m = map[string]string{}
+	}
+	(*v.m)[value[:eq]] = value[eq+1:]
+	return nil
+}
+
+func (v *stringMapValue) String() string {
+	if v.m == nil || *v.m == nil {
+		return ""
+	}
+	keys := make([]string, 0, len(*v.m))
+	for key := range *v.m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	entries := make([]string, len(keys))
+	for i, key := range keys {
+		entries[i] = key + "=" + (*v.m)[key]
+	}
+	return strings.Join(entries, ",")
+}
+
+func (v *stringMapValue) Type() string {
+	return "key=value"
+}
+
+// TypedMapField declares one key of a TypedMapFlag schema: its name, its value type
+// ("string", "int", "bool", or "float"), and the default used when the key is not
+// given on the command line.
+type TypedMapField struct {
+	Name    string
+	Type    string
+	Default interface{}
+}
+
+// TypedMapFlag defines a new flag accepting repeated "key=value" pairs typed per the
+// supplied schema. After parsing, *p holds every declared key: given keys with their
+// parsed values and omitted keys with their schema defaults, so callers get a fully
+// populated options bag. Unknown keys error. Completion offers the declared keys.
+func TypedMapFlag(cmd *cobra.Command, p *map[string]interface{}, name, shorthand string, schema []TypedMapField, usage string) *pflag.Flag {
+	m := make(map[string]interface{}, len(schema))
+	for _, field := range schema {
+		m[field.Name] = field.Default
+	}
+	*p = m
+	f := cmd.Flags().VarPF(&typedMapValue{values: p, schema: schema}, name, shorthand, usage)
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var keys []string
+		for _, field := range schema {
+			keys = append(keys, field.Name+"=")
+		}
+		return keys, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	})
+	return f
+}
+
+// TypedPair holds one ordered key=value entry parsed by OrderedTypedPairsFlag.
+type TypedPair struct {
+	Key   string
+	Value interface{}
+}
+
+// OrderedTypedPairsFlag defines a new flag accepting comma-separated "key=value"
+// pairs, e.g. "--step name=build,retries=2,name=test", preserving the order given and
+// allowing duplicate keys. Values are coerced per the schema; unknown keys and bad
+// coercions error. Repeated uses of the flag append. Completion offers the declared
+// keys. At most one EnumOpts may be passed; EscapedSeparators allows a literal comma
+// inside a value.
+func OrderedTypedPairsFlag(cmd *cobra.Command, p *[]TypedPair, name, shorthand string, schema []TypedMapField, usage string, opts ...EnumOpts) *pflag.Flag {
+	val := &orderedTypedPairsValue{pairs: p, schema: schema}
+	if len(opts) > 0 {
+		assertSupportedEnumOpts("OrderedTypedPairsFlag", opts[0], "EscapedSeparators")
+		val.opts = opts[0]
+	}
+	f := cmd.Flags().VarPF(val, name, shorthand, usage)
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var keys []string
+		for _, field := range schema {
+			keys = append(keys, field.Name+"=")
+		}
+		return keys, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	})
+	return f
+}
+
+type orderedTypedPairsValue struct {
+	pairs  *[]TypedPair
+	schema []TypedMapField
+	opts   EnumOpts
+}
+
+func (v *orderedTypedPairsValue) Set(value string) error {
+	pairs := *v.pairs
+	for _, entry := range splitFlagList(value, v.opts) {
+		eq := strings.Index(entry, "=")
+		if eq < 1 {
+			return fmt.Errorf("expected \"key=value\", got %q", entry)
+		}
+		key, raw := entry[:eq], entry[eq+1:]
+		var field TypedMapField
+		found := false
+		for _, candidate := range v.schema {
+			if candidate.Name == key {
+				field, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown key %q", key)
+		}
+		parsed, err := parseTypedMapField(field, raw)
+		if err != nil {
+			return err
+		}
+		pairs = append(pairs, TypedPair{Key: key, Value: parsed})
+	}
+	*v.pairs = pairs
+	return nil
+}
+
+func (v *orderedTypedPairsValue) String() string {
+	if v.pairs == nil || len(*v.pairs) == 0 {
+		return ""
+	}
+	entries := make([]string, len(*v.pairs))
+	for i, pair := range *v.pairs {
+		entries[i] = fmt.Sprintf("%s=%v", pair.Key, pair.Value)
+	}
+	return strings.Join(entries, ",")
+}
+
+func (v *orderedTypedPairsValue) Type() string {
+	return "key=value"
+}
+
+type typedMapValue struct {
+	values *map[string]interface{}
+	schema []TypedMapField
+}
+
+func (v *typedMapValue) Set(value string) error {
+	eq := strings.Index(value, "=")
+	if eq < 1 {
+		return fmt.Errorf("expected \"key=value\", got %q", value)
+	}
+	key, raw := value[:eq], value[eq+1:]
+	field, ok := v.field(key)
+	if !ok {
+		return fmt.Errorf("unknown key %q", key)
+	}
+	parsed, err := parseTypedMapField(field, raw)
+	if err != nil {
+		return err
+	}
+	(*v.values)[key] = parsed
+	return nil
+}
+
+func (v *typedMapValue) field(name string) (TypedMapField, bool) {
+	for _, field := range v.schema {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return TypedMapField{}, false
+}
+
+func parseTypedMapField(field TypedMapField, raw string) (interface{}, error) {
+	switch field.Type {
+	case "int":
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %q expects an integer, got %q", field.Name, raw)
+		}
+		return n, nil
+	case "bool":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %q expects a boolean, got %q", field.Name, raw)
+		}
+		return b, nil
+	case "float":
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("key %q expects a number, got %q", field.Name, raw)
+		}
+		return f, nil
+	default:
+		return raw, nil
+	}
+}
+
+func (v *typedMapValue) String() string {
+	return ""
+}
+
+func (v *typedMapValue) Type() string {
+	return "key=value"
+}
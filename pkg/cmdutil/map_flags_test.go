@@ -0,0 +1,193 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedMapFlag(t *testing.T) {
+	schema := []TypedMapField{
+		{Name: "retries", Type: "int", Default: 1},
+		{Name: "verbose", Type: "bool", Default: false},
+		{Name: "label", Type: "string", Default: "none"},
+	}
+	tests := []struct {
+		name       string
+		args       []string
+		wantsMap   map[string]interface{}
+		wantsError string
+	}{
+		{
+			name:     "defaults only",
+			args:     []string{},
+			wantsMap: map[string]interface{}{"retries": 1, "verbose": false, "label": "none"},
+		},
+		{
+			name:     "partial override keeps defaults",
+			args:     []string{"--opt", "retries=3"},
+			wantsMap: map[string]interface{}{"retries": 3, "verbose": false, "label": "none"},
+		},
+		{
+			name:     "multiple overrides",
+			args:     []string{"--opt", "retries=3", "--opt", "verbose=true", "--opt", "label=ci"},
+			wantsMap: map[string]interface{}{"retries": 3, "verbose": true, "label": "ci"},
+		},
+		{
+			name:       "unknown key",
+			args:       []string{"--opt", "timeout=5"},
+			wantsError: `invalid argument "timeout=5" for "--opt" flag: unknown key "timeout"`,
+		},
+		{
+			name:       "wrong type",
+			args:       []string{"--opt", "retries=lots"},
+			wantsError: `invalid argument "retries=lots" for "--opt" flag: key "retries" expects an integer, got "lots"`,
+		},
+		{
+			name:       "missing equals",
+			args:       []string{"--opt", "retries"},
+			wantsError: `invalid argument "retries" for "--opt" flag: expected "key=value", got "retries"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts map[string]interface{}
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			TypedMapFlag(cmd, &opts, "opt", "", schema, "an option")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsMap, opts)
+		})
+	}
+}
+
+func TestStringMapFlag(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantsMap    map[string]string
+		wantsString string
+		wantsError  string
+	}{
+		{
+			name:        "sorted deterministic output",
+			args:        []string{"--set", "zone=us", "--set", "app=api"},
+			wantsMap:    map[string]string{"app": "api", "zone": "us"},
+			wantsString: "app=api,zone=us",
+		},
+		{
+			name:        "duplicate keys last-wins",
+			args:        []string{"--set", "app=api", "--set", "app=web"},
+			wantsMap:    map[string]string{"app": "web"},
+			wantsString: "app=web",
+		},
+		{
+			name:       "missing equals",
+			args:       []string{"--set", "app"},
+			wantsError: `invalid argument "app" for "--set" flag: expected "key=value", got "app"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var settings map[string]string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			f := StringMapFlag(cmd, &settings, "set", "", "a setting")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsMap, settings)
+			assert.Equal(t, tt.wantsMap, MapFlagValue(f))
+			assert.Equal(t, tt.wantsString, f.Value.String())
+		})
+	}
+}
+
+func TestOrderedTypedPairsFlag(t *testing.T) {
+	schema := []TypedMapField{
+		{Name: "name", Type: "string"},
+		{Name: "retries", Type: "int"},
+		{Name: "parallel", Type: "bool"},
+	}
+	tests := []struct {
+		name      string
+		args      []string
+		opts      []EnumOpts
+		wantPairs []TypedPair
+		wantErr   string
+	}{
+		{
+			name: "order and duplicates preserved",
+			args: []string{"--step", "name=build,retries=2,name=test"},
+			wantPairs: []TypedPair{
+				{Key: "name", Value: "build"},
+				{Key: "retries", Value: 2},
+				{Key: "name", Value: "test"},
+			},
+		},
+		{
+			name: "repeated uses append",
+			args: []string{"--step", "name=build", "--step", "parallel=true"},
+			wantPairs: []TypedPair{
+				{Key: "name", Value: "build"},
+				{Key: "parallel", Value: true},
+			},
+		},
+		{
+			name:    "unknown key",
+			args:    []string{"--step", "timeout=30"},
+			wantErr: `invalid argument "timeout=30" for "--step" flag: unknown key "timeout"`,
+		},
+		{
+			name:    "bad coercion",
+			args:    []string{"--step", "retries=lots"},
+			wantErr: `invalid argument "retries=lots" for "--step" flag: key "retries" expects an integer, got "lots"`,
+		},
+		{
+			name:    "malformed entry",
+			args:    []string{"--step", "name"},
+			wantErr: `invalid argument "name" for "--step" flag: expected "key=value", got "name"`,
+		},
+		{
+			name: "escaped comma inside a value",
+			args: []string{"--step", `name=build\,test,retries=2`},
+			opts: []EnumOpts{{EscapedSeparators: true}},
+			wantPairs: []TypedPair{
+				{Key: "name", Value: "build,test"},
+				{Key: "retries", Value: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var steps []TypedPair
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			OrderedTypedPairsFlag(cmd, &steps, "step", "", schema, "pipeline steps", tt.opts...)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPairs, steps)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package cmdutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// PositionalIntSliceFlag defines a new flag accepting exactly len(bounds)
+// comma-separated integers, where the element at each position must fall within the
+// corresponding [min, max] bound. Useful for fixed-arity tuple inputs.
+func PositionalIntSliceFlag(cmd *cobra.Command, p *[]int, name, shorthand string, bounds [][2]int, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&positionalIntSliceValue{ints: p, bounds: bounds}, name, shorthand, usage)
+}
+
+type positionalIntSliceValue struct {
+	ints   *[]int
+	bounds [][2]int
+}
+
+func (v *positionalIntSliceValue) Set(value string) error {
+	elements := strings.Split(value, ",")
+	if len(elements) != len(v.bounds) {
+		return fmt.Errorf("expected %d comma-separated values, got %d", len(v.bounds), len(elements))
+	}
+	parsed := make([]int, len(elements))
+	for i, element := range elements {
+		n, err := strconv.Atoi(strings.TrimSpace(element))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q at position %d", element, i)
+		}
+		if min, max := v.bounds[i][0], v.bounds[i][1]; n < min || n > max {
+			return fmt.Errorf("value %d at position %d must be between %d and %d", n, i, min, max)
+		}
+		parsed[i] = n
+	}
+	*v.ints = parsed
+	return nil
+}
+
+func (v *positionalIntSliceValue) String() string {
+	if v.ints == nil || *v.ints == nil {
+		return ""
+	}
+	elements := make([]string, len(*v.ints))
+	for i, n := range *v.ints {
+		elements[i] = strconv.Itoa(n)
+	}
+	return strings.Join(elements, ",")
+}
+
+func (v *positionalIntSliceValue) Type() string {
+	return "ints"
+}
+
+// DynamicBoundedIntFlag defines a new int flag whose maximum is computed by maxFn at
+// validation time rather than fixed at registration, e.g. a worker count capped at
+// runtime.NumCPU(). The check runs from PreRunE since maxFn may depend on runtime
+// conditions established after parsing.
+func DynamicBoundedIntFlag(cmd *cobra.Command, p *int, name, shorthand string, defaultValue int, maxFn func() int, usage string) *pflag.Flag {
+	cmd.Flags().IntVarP(p, name, shorthand, defaultValue, usage)
+	registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+		if max := maxFn(); *p > max {
+			return FlagErrorf("%s %d exceeds maximum %d", name, *p, max)
+		}
+		return nil
+	})
+	return cmd.Flags().Lookup(name)
+}
+
+// FixedWidthIntFlag defines a new int64 flag whose value must fit in an integer of the
+// given bit width and signedness, e.g. bits=8 signed=false for a uint8 destination.
+// Overflow is rejected at parse time rather than silently truncating downstream.
+func FixedWidthIntFlag(cmd *cobra.Command, p *int64, name, shorthand string, bits int, signed bool, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&fixedWidthIntValue{int: p, bits: bits, signed: signed}, name, shorthand, usage)
+}
+
+type fixedWidthIntValue struct {
+	int    *int64
+	bits   int
+	signed bool
+}
+
+func (v *fixedWidthIntValue) Set(value string) error {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+	var min, max int64
+	typeName := fmt.Sprintf("uint%d", v.bits)
+	if v.signed {
+		typeName = fmt.Sprintf("int%d", v.bits)
+		max = 1<<(v.bits-1) - 1
+		min = -1 << (v.bits - 1)
+	} else {
+		max = 1<<v.bits - 1
+	}
+	if n > max {
+		return fmt.Errorf("value %d does not fit in %s (max %d)", n, typeName, max)
+	}
+	if n < min {
+		return fmt.Errorf("value %d does not fit in %s (min %d)", n, typeName, min)
+	}
+	*v.int = n
+	return nil
+}
+
+func (v *fixedWidthIntValue) String() string {
+	return strconv.FormatInt(*v.int, 10)
+}
+
+func (v *fixedWidthIntValue) Type() string {
+	return "int"
+}
+
+// IntOrAuto holds the result of an IntOrAutoFlag: the resolved count and whether it
+// came from "auto" rather than an explicit integer.
+type IntOrAuto struct {
+	Value int
+	Auto  bool
+}
+
+// IntOrAutoFlag defines a new flag accepting either a positive integer or "auto",
+// e.g. `--jobs auto` meaning one job per CPU. "auto" is resolved by autoFn during
+// PreRunE rather than at parse time, so tests can inject a deterministic function.
+func IntOrAutoFlag(cmd *cobra.Command, p *IntOrAuto, name, shorthand string, defaultValue int, autoFn func() int, usage string) *pflag.Flag {
+	*p = IntOrAuto{Value: defaultValue}
+	registerFlagCheck(cmd, func(cmd *cobra.Command) error {
+		if p.Auto {
+			p.Value = autoFn()
+		}
+		return nil
+	})
+	return cmd.Flags().VarPF(&intOrAutoValue{value: p}, name, shorthand, usage)
+}
+
+type intOrAutoValue struct {
+	value *IntOrAuto
+}
+
+func (v *intOrAutoValue) Set(value string) error {
+	if value == "auto" {
+		*v.value = IntOrAuto{Auto: true}
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("expected a positive integer or \"auto\", got %q", value)
+	}
+	if n <= 0 {
+		return fmt.Errorf("value must be a positive integer")
+	}
+	*v.value = IntOrAuto{Value: n}
+	return nil
+}
+
+func (v *intOrAutoValue) String() string {
+	if v.value == nil {
+		return ""
+	}
+	if v.value.Auto {
+		return "auto"
+	}
+	return strconv.Itoa(v.value.Value)
+}
+
+func (v *intOrAutoValue) Type() string {
+	return "int|auto"
+}
+
+// PercentileOpts customizes the behavior of PercentileFlag.
+type PercentileOpts struct {
+	// AsFraction stores the value divided by 100, e.g. "--p 95" stores 0.95, for
+	// callers whose downstream math expects a 0..1 fraction.
+	AsFraction bool
+}
+
+// PercentileFlag defines a new float flag accepting a percentile in [0, 100], given as
+// an integer or decimal, e.g. "--p 99.9" for latency reporting. defaultValue uses the
+// same 0..100 scale as command-line input, and is converted along with it when
+// AsFraction is set. At most one PercentileOpts may be passed.
+func PercentileFlag(cmd *cobra.Command, p *float64, name, shorthand string, defaultValue float64, usage string, opts ...PercentileOpts) *pflag.Flag {
+	val := &percentileValue{float: p}
+	if len(opts) > 0 {
+		val.opts = opts[0]
+	}
+	*p = defaultValue
+	if val.opts.AsFraction {
+		*p = defaultValue / 100
+	}
+	return cmd.Flags().VarPF(val, name, shorthand, usage)
+}
+
+type percentileValue struct {
+	float *float64
+	opts  PercentileOpts
+}
+
+func (v *percentileValue) Set(value string) error {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	if n < 0 || n > 100 {
+		return fmt.Errorf("percentile must be between 0 and 100")
+	}
+	if v.opts.AsFraction {
+		n /= 100
+	}
+	*v.float = n
+	return nil
+}
+
+func (v *percentileValue) String() string {
+	return strconv.FormatFloat(*v.float, 'f', -1, 64)
+}
+
+func (v *percentileValue) Type() string {
+	return "float"
+}
+
+// PowerOfTwoIntFlag defines a new int flag that only accepts positive powers of two,
+// e.g. for alignment or block-size values.
+func PowerOfTwoIntFlag(cmd *cobra.Command, p *int, name, shorthand string, defaultValue int, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&powerOfTwoValue{int: p}, name, shorthand, usage)
+}
+
+// SteppedIntFlag defines a new int flag whose value must lie within [min, max] and be
+// reachable from min in increments of step, e.g. multiples of 5 between 0 and 100.
+// Completion offers the discrete valid values.
+func SteppedIntFlag(cmd *cobra.Command, p *int, name, shorthand string, min, max, step, defaultValue int, usage string) *pflag.Flag {
+	*p = defaultValue
+	f := cmd.Flags().VarPF(&steppedIntValue{int: p, min: min, max: max, step: step}, name, shorthand, usage)
+	_ = cmd.RegisterFlagCompletionFunc(name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var values []string
+		for n := min; n <= max; n += step {
+			values = append(values, strconv.Itoa(n))
+		}
+		return values, cobra.ShellCompDirectiveNoFileComp
+	})
+	return f
+}
+
+type steppedIntValue struct {
+	int            *int
+	min, max, step int
+}
+
+func (v *steppedIntValue) Set(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	if n < v.min || n > v.max || (n-v.min)%v.step != 0 {
+		return fmt.Errorf("value must be a multiple of %d between %d and %d", v.step, v.min, v.max)
+	}
+	*v.int = n
+	return nil
+}
+
+func (v *steppedIntValue) String() string {
+	return strconv.Itoa(*v.int)
+}
+
+func (v *steppedIntValue) Type() string {
+	return "int"
+}
+
+type powerOfTwoValue struct {
+	int *int
+}
+
+func (v *powerOfTwoValue) Set(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	if n <= 0 || n&(n-1) != 0 {
+		return fmt.Errorf("value must be a power of two")
+	}
+	*v.int = n
+	return nil
+}
+
+func (v *powerOfTwoValue) String() string {
+	return strconv.Itoa(*v.int)
+}
+
+func (v *powerOfTwoValue) Type() string {
+	return "int"
+}
@@ -0,0 +1,403 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionalIntSliceFlag(t *testing.T) {
+	bounds := [][2]int{{0, 100}, {0, 200}, {0, 300}}
+	tests := []struct {
+		name       string
+		value      string
+		wantsInts  []int
+		wantsError string
+	}{
+		{
+			name:      "valid tuple",
+			value:     "10,20,30",
+			wantsInts: []int{10, 20, 30},
+		},
+		{
+			name:       "wrong arity",
+			value:      "10,20",
+			wantsError: `invalid argument "10,20" for "--coords" flag: expected 3 comma-separated values, got 2`,
+		},
+		{
+			name:       "out of positional bound",
+			value:      "10,201,30",
+			wantsError: `invalid argument "10,201,30" for "--coords" flag: value 201 at position 1 must be between 0 and 200`,
+		},
+		{
+			name:       "not an integer",
+			value:      "10,twenty,30",
+			wantsError: `invalid argument "10,twenty,30" for "--coords" flag: invalid integer "twenty" at position 1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var coords []int
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			PositionalIntSliceFlag(cmd, &coords, "coords", "", bounds, "the coordinates")
+			cmd.SetArgs([]string{"--coords", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsInts, coords)
+		})
+	}
+}
+
+func TestSteppedIntFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantsValue int
+		wantsError string
+	}{
+		{
+			name:       "no flag uses default",
+			args:       []string{},
+			wantsValue: 80,
+		},
+		{
+			name:       "valid step",
+			args:       []string{"--quality", "95"},
+			wantsValue: 95,
+		},
+		{
+			name:       "lower bound",
+			args:       []string{"--quality", "0"},
+			wantsValue: 0,
+		},
+		{
+			name:       "off step",
+			args:       []string{"--quality", "42"},
+			wantsError: `invalid argument "42" for "--quality" flag: value must be a multiple of 5 between 0 and 100`,
+		},
+		{
+			name:       "out of range",
+			args:       []string{"--quality", "105"},
+			wantsError: `invalid argument "105" for "--quality" flag: value must be a multiple of 5 between 0 and 100`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var quality int
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			SteppedIntFlag(cmd, &quality, "quality", "", 0, 100, 5, 80, "the quality")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, quality)
+		})
+	}
+}
+
+func TestPowerOfTwoIntFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantsValue int
+		wantsError string
+	}{
+		{
+			name:       "no flag uses default",
+			args:       []string{},
+			wantsValue: 512,
+		},
+		{
+			name:       "power of two",
+			args:       []string{"--block-size", "4096"},
+			wantsValue: 4096,
+		},
+		{
+			name:       "one",
+			args:       []string{"--block-size", "1"},
+			wantsValue: 1,
+		},
+		{
+			name:       "not a power of two",
+			args:       []string{"--block-size", "100"},
+			wantsError: `invalid argument "100" for "--block-size" flag: value must be a power of two`,
+		},
+		{
+			name:       "zero",
+			args:       []string{"--block-size", "0"},
+			wantsError: `invalid argument "0" for "--block-size" flag: value must be a power of two`,
+		},
+		{
+			name:       "negative",
+			args:       []string{"--block-size", "-8"},
+			wantsError: `invalid argument "-8" for "--block-size" flag: value must be a power of two`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var size int
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			PowerOfTwoIntFlag(cmd, &size, "block-size", "", 512, "the block size")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, size)
+		})
+	}
+}
+
+func TestDynamicBoundedIntFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		max        int
+		wantsValue int
+		wantsError string
+	}{
+		{
+			name:       "within bound",
+			args:       []string{"--workers", "4"},
+			max:        8,
+			wantsValue: 4,
+		},
+		{
+			name:       "default within bound",
+			args:       []string{},
+			max:        8,
+			wantsValue: 2,
+		},
+		{
+			name:       "exceeds bound",
+			args:       []string{"--workers", "64"},
+			max:        8,
+			wantsError: "workers 64 exceeds maximum 8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var workers int
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			DynamicBoundedIntFlag(cmd, &workers, "workers", "", 2, func() int { return tt.max }, "number of workers")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, workers)
+		})
+	}
+}
+
+func TestFixedWidthIntFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		bits       int
+		signed     bool
+		value      string
+		wantsValue int64
+		wantsError string
+	}{
+		{
+			name:       "fits in uint8",
+			bits:       8,
+			value:      "255",
+			wantsValue: 255,
+		},
+		{
+			name:       "overflows uint8",
+			bits:       8,
+			value:      "300",
+			wantsError: `invalid argument "300" for "--value" flag: value 300 does not fit in uint8 (max 255)`,
+		},
+		{
+			name:       "negative rejected for unsigned",
+			bits:       8,
+			value:      "-1",
+			wantsError: `invalid argument "-1" for "--value" flag: value -1 does not fit in uint8 (min 0)`,
+		},
+		{
+			name:       "fits in int16",
+			bits:       16,
+			signed:     true,
+			value:      "-32768",
+			wantsValue: -32768,
+		},
+		{
+			name:       "underflows int16",
+			bits:       16,
+			signed:     true,
+			value:      "-32769",
+			wantsError: `invalid argument "-32769" for "--value" flag: value -32769 does not fit in int16 (min -32768)`,
+		},
+		{
+			name:       "overflows int16",
+			bits:       16,
+			signed:     true,
+			value:      "32768",
+			wantsError: `invalid argument "32768" for "--value" flag: value 32768 does not fit in int16 (max 32767)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value int64
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			FixedWidthIntFlag(cmd, &value, "value", "", tt.bits, tt.signed, "the value")
+			cmd.SetArgs([]string{"--value", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, value)
+		})
+	}
+}
+
+func TestPercentileFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		opts    []PercentileOpts
+		wants   float64
+		wantErr string
+	}{
+		{
+			name:  "integer percentile",
+			args:  []string{"--p", "95"},
+			wants: 95,
+		},
+		{
+			name:  "decimal percentile",
+			args:  []string{"--p", "99.9"},
+			wants: 99.9,
+		},
+		{
+			name:  "unset keeps default",
+			args:  []string{},
+			wants: 50,
+		},
+		{
+			name:    "above range",
+			args:    []string{"--p", "100.1"},
+			wantErr: `invalid argument "100.1" for "--p" flag: percentile must be between 0 and 100`,
+		},
+		{
+			name:    "negative",
+			args:    []string{"--p", "-1"},
+			wantErr: `invalid argument "-1" for "--p" flag: percentile must be between 0 and 100`,
+		},
+		{
+			name:  "stored as fraction",
+			args:  []string{"--p", "95"},
+			opts:  []PercentileOpts{{AsFraction: true}},
+			wants: 0.95,
+		},
+		{
+			name:  "unset default stored as fraction",
+			args:  []string{},
+			opts:  []PercentileOpts{{AsFraction: true}},
+			wants: 0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p float64
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			PercentileFlag(cmd, &p, "p", "", 50, "percentile to report", tt.opts...)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wants, p)
+		})
+	}
+}
+
+func TestIntOrAutoFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wants   IntOrAuto
+		wantErr string
+	}{
+		{
+			name:  "explicit integer",
+			args:  []string{"--jobs", "4"},
+			wants: IntOrAuto{Value: 4},
+		},
+		{
+			name:  "auto resolved via injected function",
+			args:  []string{"--jobs", "auto"},
+			wants: IntOrAuto{Value: 8, Auto: true},
+		},
+		{
+			name:  "unset keeps default",
+			args:  []string{},
+			wants: IntOrAuto{Value: 1},
+		},
+		{
+			name:    "zero rejected",
+			args:    []string{"--jobs", "0"},
+			wantErr: `invalid argument "0" for "--jobs" flag: value must be a positive integer`,
+		},
+		{
+			name:    "negative rejected",
+			args:    []string{"--jobs", "-2"},
+			wantErr: `invalid argument "-2" for "--jobs" flag: value must be a positive integer`,
+		},
+		{
+			name:    "garbage rejected",
+			args:    []string{"--jobs", "many"},
+			wantErr: `invalid argument "many" for "--jobs" flag: expected a positive integer or "auto", got "many"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var jobs IntOrAuto
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			IntOrAutoFlag(cmd, &jobs, "jobs", "", 1, func() int { return 8 }, "number of parallel jobs")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wants, jobs)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package cmdutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ResolvedPathOpts customizes the behavior of ResolvedPathFlag.
+type ResolvedPathOpts struct {
+	// AllowMissing skips symlink resolution and stores the cleaned path as given when
+	// the path does not exist yet, e.g. for flags naming a file to be created.
+	AllowMissing bool
+}
+
+// ResolvedPathFlag defines a new string flag that resolves symlinks in its value via
+// filepath.EvalSymlinks and stores the resolved path. By default the path must exist.
+func ResolvedPathFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue string, opts ResolvedPathOpts, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&resolvedPathValue{path: p, opts: opts}, name, shorthand, usage)
+}
+
+// WritablePathOpts customizes the behavior of WritablePathFlag.
+type WritablePathOpts struct {
+	// MkdirParents creates missing parent directories instead of erroring.
+	MkdirParents bool
+}
+
+// WritablePathFlag defines a new string flag naming a file to be written, validating at
+// parse time that the parent directory exists and is writable so the command doesn't do
+// all its work only to fail at the final write.
+func WritablePathFlag(cmd *cobra.Command, p *string, name, shorthand string, opts WritablePathOpts, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&writablePathValue{path: p, opts: opts}, name, shorthand, usage)
+}
+
+// GlobOpts customizes the behavior of GlobFlag.
+type GlobOpts struct {
+	// Templates lists glob patterns the user's glob itself must match, constraining
+	// its shape, e.g. "*.?*" to require an extension glob and rule out a dangerous
+	// bare "*". Any shape is accepted when empty.
+	Templates []string
+}
+
+// GlobFlag defines a new string flag that accepts a glob pattern, validating that it
+// compiles and, when opts.Templates is set, that it conforms to one of the allowed
+// shapes. At most one GlobOpts may be passed.
+func GlobFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string, opts ...GlobOpts) *pflag.Flag {
+	*p = defaultValue
+	val := &globValue{string: p}
+	if len(opts) > 0 {
+		val.opts = opts[0]
+	}
+	return cmd.Flags().VarPF(val, name, shorthand, usage)
+}
+
+type globValue struct {
+	string *string
+	opts   GlobOpts
+}
+
+func (v *globValue) Set(value string) error {
+	if _, err := filepath.Match(value, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %v", value, err)
+	}
+	if len(v.opts.Templates) > 0 {
+		conforms := false
+		for _, template := range v.opts.Templates {
+			if ok, _ := filepath.Match(template, value); ok {
+				conforms = true
+				break
+			}
+		}
+		if !conforms {
+			return fmt.Errorf("glob %q does not match an allowed shape: %s", value, strings.Join(v.opts.Templates, ", "))
+		}
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *globValue) String() string {
+	if v.string == nil {
+		return ""
+	}
+	return *v.string
+}
+
+func (v *globValue) Type() string {
+	return "glob"
+}
+
+// SandboxedPathFlag defines a new string flag for a path that must stay within rootDir:
+// the value is cleaned and joined against rootDir and traversal attempts such as
+// "../../etc/passwd" are rejected. The safe joined path is stored.
+func SandboxedPathFlag(cmd *cobra.Command, p *string, name, shorthand, rootDir, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&sandboxedPathValue{path: p, root: rootDir}, name, shorthand, usage)
+}
+
+type sandboxedPathValue struct {
+	path *string
+	root string
+}
+
+func (v *sandboxedPathValue) Set(value string) error {
+	joined := filepath.Join(v.root, value)
+	rel, err := filepath.Rel(v.root, joined)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("path %q escapes %s", value, v.root)
+	}
+	*v.path = joined
+	return nil
+}
+
+func (v *sandboxedPathValue) String() string {
+	if v.path == nil {
+		return ""
+	}
+	return *v.path
+}
+
+func (v *sandboxedPathValue) Type() string {
+	return "file"
+}
+
+type writablePathValue struct {
+	path *string
+	opts WritablePathOpts
+}
+
+func (v *writablePathValue) Set(value string) error {
+	parent := filepath.Dir(value)
+	if v.opts.MkdirParents {
+		if err := os.MkdirAll(parent, 0755); err != nil {
+			return fmt.Errorf("could not create directory %s: %w", parent, err)
+		}
+	} else if info, err := os.Stat(parent); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("directory does not exist: %s", parent)
+		}
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", parent)
+	}
+	probe, err := ioutil.TempFile(parent, ".write-check-")
+	if err != nil {
+		return fmt.Errorf("directory is not writable: %s", parent)
+	}
+	probe.Close()
+	_ = os.Remove(probe.Name())
+	*v.path = value
+	return nil
+}
+
+func (v *writablePathValue) String() string {
+	if v.path == nil {
+		return ""
+	}
+	return *v.path
+}
+
+func (v *writablePathValue) Type() string {
+	return "file"
+}
+
+type resolvedPathValue struct {
+	path *string
+	opts ResolvedPathOpts
+}
+
+func (v *resolvedPathValue) Set(value string) error {
+	resolved, err := filepath.EvalSymlinks(value)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if v.opts.AllowMissing {
+				*v.path = filepath.Clean(value)
+				return nil
+			}
+			return fmt.Errorf("path does not exist: %s", value)
+		}
+		return err
+	}
+	*v.path = resolved
+	return nil
+}
+
+func (v *resolvedPathValue) String() string {
+	return *v.path
+}
+
+func (v *resolvedPathValue) Type() string {
+	return "file"
+}
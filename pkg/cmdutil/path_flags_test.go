@@ -0,0 +1,216 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritablePathFlag(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		opts       WritablePathOpts
+		value      string
+		wantsError string
+	}{
+		{
+			name:  "writable parent",
+			value: filepath.Join(dir, "out.txt"),
+		},
+		{
+			name:       "missing parent",
+			value:      filepath.Join(dir, "sub", "out.txt"),
+			wantsError: "directory does not exist: " + filepath.Join(dir, "sub"),
+		},
+		{
+			name:  "missing parent created",
+			opts:  WritablePathOpts{MkdirParents: true},
+			value: filepath.Join(dir, "deep", "nested", "out.txt"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			WritablePathFlag(cmd, &path, "output", "", tt.opts, "the output file")
+			cmd.SetArgs([]string{"--output", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, path)
+		})
+	}
+}
+
+func TestResolvedPathFlag(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real.txt")
+	require.NoError(t, ioutil.WriteFile(target, []byte("data"), 0600))
+	link := filepath.Join(dir, "link.txt")
+	require.NoError(t, os.Symlink(target, link))
+
+	tests := []struct {
+		name       string
+		opts       ResolvedPathOpts
+		value      string
+		wantsPath  string
+		wantsError string
+	}{
+		{
+			name:      "resolves symlink",
+			value:     link,
+			wantsPath: target,
+		},
+		{
+			name:      "regular file",
+			value:     target,
+			wantsPath: target,
+		},
+		{
+			name:       "missing path",
+			value:      filepath.Join(dir, "nope.txt"),
+			wantsError: "path does not exist: " + filepath.Join(dir, "nope.txt"),
+		},
+		{
+			name:      "missing path allowed",
+			opts:      ResolvedPathOpts{AllowMissing: true},
+			value:     filepath.Join(dir, "sub", "..", "new.txt"),
+			wantsPath: filepath.Join(dir, "new.txt"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			ResolvedPathFlag(cmd, &path, "target", "", "", tt.opts, "the target path")
+			cmd.SetArgs([]string{"--target", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsPath, path)
+		})
+	}
+}
+
+func TestSandboxedPathFlag(t *testing.T) {
+	root := filepath.Join("/srv", "data")
+	tests := []struct {
+		name       string
+		value      string
+		wantsPath  string
+		wantsError string
+	}{
+		{
+			name:      "relative path joined",
+			value:     "reports/latest.csv",
+			wantsPath: filepath.Join(root, "reports", "latest.csv"),
+		},
+		{
+			name:      "interior dot-dot that stays inside",
+			value:     "reports/../exports/a.csv",
+			wantsPath: filepath.Join(root, "exports", "a.csv"),
+		},
+		{
+			name:       "traversal escapes root",
+			value:      "../../etc/passwd",
+			wantsError: `invalid argument "../../etc/passwd" for "--path" flag: path "../../etc/passwd" escapes /srv/data`,
+		},
+		{
+			name:       "dot-dot after clean escapes",
+			value:      "reports/../../secrets",
+			wantsError: `invalid argument "reports/../../secrets" for "--path" flag: path "reports/../../secrets" escapes /srv/data`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			SandboxedPathFlag(cmd, &path, "path", "", root, "the data path")
+			cmd.SetArgs([]string{"--path", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsPath, path)
+		})
+	}
+}
+
+func TestGlobFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		opts     GlobOpts
+		wantGlob string
+		wantErr  string
+	}{
+		{
+			name:     "valid glob",
+			args:     []string{"--match", "*.go"},
+			wantGlob: "*.go",
+		},
+		{
+			name:    "malformed glob",
+			args:    []string{"--match", "[a-"},
+			wantErr: `invalid argument "[a-" for "--match" flag: invalid glob "[a-": syntax error in pattern`,
+		},
+		{
+			name:     "conforming glob",
+			args:     []string{"--match", "*.go"},
+			opts:     GlobOpts{Templates: []string{"*.?*"}},
+			wantGlob: "*.go",
+		},
+		{
+			name:    "bare wildcard rejected by template",
+			args:    []string{"--match", "*"},
+			opts:    GlobOpts{Templates: []string{"*.?*"}},
+			wantErr: `invalid argument "*" for "--match" flag: glob "*" does not match an allowed shape: *.?*`,
+		},
+		{
+			name:     "matches any of several templates",
+			args:     []string{"--match", "cmd/*"},
+			opts:     GlobOpts{Templates: []string{"*.?*", "cmd/*"}},
+			wantGlob: "cmd/*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var glob string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			GlobFlag(cmd, &glob, "match", "", "", "glob to match", tt.opts)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantGlob, glob)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package cmdutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Schedule is a tagged union of the two scheduling forms accepted by ScheduleFlag:
+// exactly one of Cron or Every is set.
+type Schedule struct {
+	// Cron holds a five-field cron expression, e.g. "0 * * * *".
+	Cron string
+	// Every holds a fixed interval, e.g. 5 * time.Minute.
+	Every time.Duration
+}
+
+// IsCron reports whether the schedule was given as a cron expression.
+func (s Schedule) IsCron() bool {
+	return s.Cron != ""
+}
+
+// ScheduleFlag defines a new flag that accepts either a five-field cron expression
+// ("0 * * * *") or a plain interval ("5m"), detecting which form was given and storing
+// the result as a tagged union.
+func ScheduleFlag(cmd *cobra.Command, p *Schedule, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&scheduleValue{schedule: p}, name, shorthand, usage)
+}
+
+type scheduleValue struct {
+	schedule *Schedule
+}
+
+func (v *scheduleValue) Set(value string) error {
+	if fields := strings.Fields(value); len(fields) == 5 {
+		if err := validateCronFields(fields); err != nil {
+			return fmt.Errorf("invalid cron expression %q: %v", value, err)
+		}
+		*v.schedule = Schedule{Cron: strings.Join(fields, " ")}
+		return nil
+	}
+	every, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("expected a cron expression or an interval such as \"5m\", got %q", value)
+	}
+	if every <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	*v.schedule = Schedule{Every: every}
+	return nil
+}
+
+func (v *scheduleValue) String() string {
+	if v.schedule == nil {
+		return ""
+	}
+	if v.schedule.IsCron() {
+		return v.schedule.Cron
+	}
+	if v.schedule.Every > 0 {
+		return v.schedule.Every.String()
+	}
+	return ""
+}
+
+func (v *scheduleValue) Type() string {
+	return "schedule"
+}
+
+// cronFieldBounds are the value ranges of the five cron fields in order: minute,
+// hour, day of month, month, and day of week (0-7, both 0 and 7 meaning Sunday).
+var cronFieldBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+var cronFieldNames = [5]string{"minute", "hour", "day of month", "month", "day of week"}
+
+func validateCronFields(fields []string) error {
+	for i, field := range fields {
+		if err := validateCronField(field, cronFieldBounds[i][0], cronFieldBounds[i][1]); err != nil {
+			return fmt.Errorf("bad %s field %q: %v", cronFieldNames[i], field, err)
+		}
+	}
+	return nil
+}
+
+// validateCronField checks one cron field, supporting "*", lists, ranges, and steps.
+func validateCronField(field string, min, max int) error {
+	for _, part := range strings.Split(field, ",") {
+		expr, step := part, ""
+		if i := strings.Index(part, "/"); i >= 0 {
+			expr, step = part[:i], part[i+1:]
+			n, err := strconv.Atoi(step)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("step must be a positive integer")
+			}
+		}
+		if expr == "*" {
+			continue
+		}
+		lo, hi := expr, expr
+		if i := strings.Index(expr, "-"); i >= 0 {
+			lo, hi = expr[:i], expr[i+1:]
+		}
+		loN, err := strconv.Atoi(lo)
+		if err != nil {
+			return fmt.Errorf("values must be integers")
+		}
+		hiN, err := strconv.Atoi(hi)
+		if err != nil {
+			return fmt.Errorf("values must be integers")
+		}
+		if loN < min || hiN > max || loN > hiN {
+			return fmt.Errorf("values must be in range %d-%d", min, max)
+		}
+	}
+	return nil
+}
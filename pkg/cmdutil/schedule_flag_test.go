@@ -0,0 +1,68 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleFlag(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		wantsSchedule Schedule
+		wantsError    string
+	}{
+		{
+			name:          "hourly cron",
+			value:         "0 * * * *",
+			wantsSchedule: Schedule{Cron: "0 * * * *"},
+		},
+		{
+			name:          "cron with ranges, lists, and steps",
+			value:         "*/15 9-17 1,15 * 1-5",
+			wantsSchedule: Schedule{Cron: "*/15 9-17 1,15 * 1-5"},
+		},
+		{
+			name:          "interval",
+			value:         "5m",
+			wantsSchedule: Schedule{Every: 5 * time.Minute},
+		},
+		{
+			name:       "cron field out of range",
+			value:      "0 25 * * *",
+			wantsError: `invalid argument "0 25 * * *" for "--schedule" flag: invalid cron expression "0 25 * * *": bad hour field "25": values must be in range 0-23`,
+		},
+		{
+			name:       "neither form",
+			value:      "whenever",
+			wantsError: `invalid argument "whenever" for "--schedule" flag: expected a cron expression or an interval such as "5m", got "whenever"`,
+		},
+		{
+			name:       "negative interval",
+			value:      "-5m",
+			wantsError: `invalid argument "-5m" for "--schedule" flag: interval must be positive`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schedule Schedule
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			ScheduleFlag(cmd, &schedule, "schedule", "", "when to run")
+			cmd.SetArgs([]string{"--schedule", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsSchedule, schedule)
+			assert.Equal(t, tt.wantsSchedule.Cron != "", schedule.IsCron())
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package cmdutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ByteSizeFlag defines a new flag that accepts a human-readable byte size such as
+// "512KB" or "1.5GB" and stores the parsed number of bytes. Units are powers of 1024;
+// a bare number is interpreted as bytes.
+func ByteSizeFlag(cmd *cobra.Command, p *int64, name, shorthand string, defaultValue int64, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&byteSizeValue{size: p}, name, shorthand, usage)
+}
+
+// BoundedByteSizeFlag is like ByteSizeFlag but additionally validates that the parsed
+// value falls within [min, max], where the bounds are themselves size strings so the
+// call site stays readable. Errors report the bounds in their original units.
+func BoundedByteSizeFlag(cmd *cobra.Command, p *int64, name, shorthand, defaultValue, min, max, usage string) *pflag.Flag {
+	val := &byteSizeValue{size: p, min: min, max: max}
+	if defaultValue != "" {
+		if err := val.Set(defaultValue); err != nil {
+			panic(fmt.Sprintf("invalid default %q for --%s: %v", defaultValue, name, err))
+		}
+	}
+	return cmd.Flags().VarPF(val, name, shorthand, usage)
+}
+
+// SizeOrPercent holds a resource limit given either as an absolute byte size or as a
+// percentage of a total known only at runtime. Use Resolve to turn it into bytes.
+type SizeOrPercent struct {
+	Bytes     int64
+	Percent   float64
+	IsPercent bool
+}
+
+// Resolve returns the limit in bytes, applying the percentage to total when the value
+// was given as a percentage.
+func (s SizeOrPercent) Resolve(total int64) int64 {
+	if s.IsPercent {
+		return int64(float64(total) * s.Percent / 100)
+	}
+	return s.Bytes
+}
+
+// SizeOrPercentFlag defines a new flag that accepts either a byte size ("512MB") or a
+// percentage ("25%"), e.g. for limits relative to available memory. defaultValue uses
+// the same syntax; percentages must be between 0 and 100.
+func SizeOrPercentFlag(cmd *cobra.Command, p *SizeOrPercent, name, shorthand, defaultValue, usage string) *pflag.Flag {
+	val := &sizeOrPercentValue{value: p}
+	if defaultValue != "" {
+		if err := val.Set(defaultValue); err != nil {
+			panic(fmt.Sprintf("invalid default %q for --%s: %v", defaultValue, name, err))
+		}
+	}
+	return cmd.Flags().VarPF(val, name, shorthand, usage)
+}
+
+type sizeOrPercentValue struct {
+	value *SizeOrPercent
+}
+
+func (v *sizeOrPercentValue) Set(value string) error {
+	if strings.HasSuffix(strings.TrimSpace(value), "%") {
+		raw := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+		percent, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid percentage %q", value)
+		}
+		if percent < 0 || percent > 100 {
+			return fmt.Errorf("percentage must be between 0%% and 100%%")
+		}
+		*v.value = SizeOrPercent{Percent: percent, IsPercent: true}
+		return nil
+	}
+	size, err := parseByteSize(value)
+	if err != nil {
+		return err
+	}
+	*v.value = SizeOrPercent{Bytes: size}
+	return nil
+}
+
+func (v *sizeOrPercentValue) String() string {
+	if v.value == nil {
+		return ""
+	}
+	if v.value.IsPercent {
+		return fmt.Sprintf("%g%%", v.value.Percent)
+	}
+	return strconv.FormatInt(v.value.Bytes, 10)
+}
+
+func (v *sizeOrPercentValue) Type() string {
+	return "size"
+}
+
+type byteSizeValue struct {
+	size     *int64
+	min, max string
+}
+
+func (v *byteSizeValue) Set(value string) error {
+	size, err := parseByteSize(value)
+	if err != nil {
+		return err
+	}
+	if v.min != "" {
+		min, err := parseByteSize(v.min)
+		if err != nil {
+			return fmt.Errorf("invalid minimum size %q: %v", v.min, err)
+		}
+		if size < min {
+			return fmt.Errorf("must be between %s and %s", v.min, v.max)
+		}
+	}
+	if v.max != "" {
+		max, err := parseByteSize(v.max)
+		if err != nil {
+			return fmt.Errorf("invalid maximum size %q: %v", v.max, err)
+		}
+		if size > max {
+			return fmt.Errorf("must be between %s and %s", v.min, v.max)
+		}
+	}
+	*v.size = size
+	return nil
+}
+
+var byteSizeUnits = map[string]int64{
+	"":   1,
+	"b":  1,
+	"k":  1 << 10,
+	"kb": 1 << 10,
+	"m":  1 << 20,
+	"mb": 1 << 20,
+	"g":  1 << 30,
+	"gb": 1 << 30,
+	"t":  1 << 40,
+	"tb": 1 << 40,
+}
+
+func parseByteSize(value string) (int64, error) {
+	s := strings.TrimSpace(value)
+	numEnd := len(s)
+	for numEnd > 0 {
+		c := s[numEnd-1]
+		if c >= '0' && c <= '9' || c == '.' {
+			break
+		}
+		numEnd--
+	}
+	num, unit := s[:numEnd], strings.ToLower(strings.TrimSpace(s[numEnd:]))
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok || num == "" {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+func (v *byteSizeValue) String() string {
+	if v.size == nil {
+		return "0"
+	}
+	return strconv.FormatInt(*v.size, 10)
+}
+
+func (v *byteSizeValue) Type() string {
+	return "size"
+}
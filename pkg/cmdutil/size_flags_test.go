@@ -0,0 +1,116 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundedByteSizeFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantsSize  int64
+		wantsError string
+	}{
+		{
+			name:      "no flag uses default",
+			args:      []string{},
+			wantsSize: 100 << 20,
+		},
+		{
+			name:      "size with unit",
+			args:      []string{"--max-size", "256MB"},
+			wantsSize: 256 << 20,
+		},
+		{
+			name:      "fractional size",
+			args:      []string{"--max-size", "1.5MB"},
+			wantsSize: 3 << 19,
+		},
+		{
+			name:       "below minimum",
+			args:       []string{"--max-size", "512KB"},
+			wantsError: `invalid argument "512KB" for "--max-size" flag: must be between 1MB and 1GB`,
+		},
+		{
+			name:       "above maximum",
+			args:       []string{"--max-size", "2GB"},
+			wantsError: `invalid argument "2GB" for "--max-size" flag: must be between 1MB and 1GB`,
+		},
+		{
+			name:       "malformed size",
+			args:       []string{"--max-size", "12wat"},
+			wantsError: `invalid argument "12wat" for "--max-size" flag: invalid size "12wat"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var size int64
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			BoundedByteSizeFlag(cmd, &size, "max-size", "", "100MB", "1MB", "1GB", "maximum size")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsSize, size)
+		})
+	}
+}
+
+func TestSizeOrPercentFlag(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		total         int64
+		wantsResolved int64
+		wantsError    string
+	}{
+		{
+			name:          "absolute size",
+			value:         "512MB",
+			total:         1 << 31,
+			wantsResolved: 512 << 20,
+		},
+		{
+			name:          "percentage of total",
+			value:         "25%",
+			total:         1 << 30,
+			wantsResolved: 1 << 28,
+		},
+		{
+			name:       "percentage over 100",
+			value:      "150%",
+			wantsError: `invalid argument "150%" for "--cache-size" flag: percentage must be between 0% and 100%`,
+		},
+		{
+			name:       "garbage",
+			value:      "lots",
+			wantsError: `invalid argument "lots" for "--cache-size" flag: invalid size "lots"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var size SizeOrPercent
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			SizeOrPercentFlag(cmd, &size, "cache-size", "", "10%", "the cache size")
+			cmd.SetArgs([]string{"--cache-size", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsResolved, size.Resolve(tt.total))
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package cmdutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// SQLIdentOpts customizes the behavior of SQLIdentFlag.
+type SQLIdentOpts struct {
+	// Reserved lists words that may not be used as identifiers, compared
+	// case-insensitively. Defaults to a small set of common SQL keywords.
+	Reserved []string
+	// Quote stores the identifier wrapped in double quotes.
+	Quote bool
+}
+
+var defaultSQLReservedWords = []string{
+	"all", "and", "as", "between", "by", "case", "delete", "distinct", "drop",
+	"from", "group", "having", "in", "insert", "into", "join", "like", "not",
+	"null", "or", "order", "select", "set", "table", "union", "update", "where",
+}
+
+// SQLIdentFlag defines a new string flag that accepts a safe SQL identifier: letters,
+// digits, and underscores, not starting with a digit, and not a reserved word. This
+// catches typos and reduces injection risk for table/column flags in query tooling.
+// At most one SQLIdentOpts may be passed.
+func SQLIdentFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string, opts ...SQLIdentOpts) *pflag.Flag {
+	*p = defaultValue
+	val := &sqlIdentValue{string: p}
+	if len(opts) > 0 {
+		val.opts = opts[0]
+	}
+	if val.opts.Reserved == nil {
+		val.opts.Reserved = defaultSQLReservedWords
+	}
+	return cmd.Flags().VarPF(val, name, shorthand, usage)
+}
+
+type sqlIdentValue struct {
+	string *string
+	opts   SQLIdentOpts
+}
+
+func (v *sqlIdentValue) Set(value string) error {
+	if value == "" {
+		return fmt.Errorf("identifier cannot be empty")
+	}
+	if c := value[0]; c >= '0' && c <= '9' {
+		return fmt.Errorf("identifier cannot start with a digit")
+	}
+	for _, c := range value {
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			continue
+		}
+		return fmt.Errorf("identifier may contain only letters, digits, and underscores")
+	}
+	for _, word := range v.opts.Reserved {
+		if strings.EqualFold(word, value) {
+			return fmt.Errorf("%q is a reserved word", value)
+		}
+	}
+	if v.opts.Quote {
+		value = `"` + value + `"`
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *sqlIdentValue) String() string {
+	return *v.string
+}
+
+func (v *sqlIdentValue) Type() string {
+	return "identifier"
+}
@@ -0,0 +1,74 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLIdentFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []SQLIdentOpts
+		value      string
+		wantsValue string
+		wantsError string
+	}{
+		{
+			name:       "plain identifier",
+			value:      "user_events",
+			wantsValue: "user_events",
+		},
+		{
+			name:       "digits after first character",
+			value:      "t2",
+			wantsValue: "t2",
+		},
+		{
+			name:       "leading digit",
+			value:      "2fast",
+			wantsError: `invalid argument "2fast" for "--table" flag: identifier cannot start with a digit`,
+		},
+		{
+			name:       "punctuation",
+			value:      "users; drop",
+			wantsError: `invalid argument "users; drop" for "--table" flag: identifier may contain only letters, digits, and underscores`,
+		},
+		{
+			name:       "reserved word",
+			value:      "Select",
+			wantsError: `invalid argument "Select" for "--table" flag: "Select" is a reserved word`,
+		},
+		{
+			name:       "custom reserved list",
+			opts:       []SQLIdentOpts{{Reserved: []string{"internal"}}},
+			value:      "internal",
+			wantsError: `invalid argument "internal" for "--table" flag: "internal" is a reserved word`,
+		},
+		{
+			name:       "quoted storage",
+			opts:       []SQLIdentOpts{{Quote: true}},
+			value:      "events",
+			wantsValue: `"events"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var table string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			SQLIdentFlag(cmd, &table, "table", "", "", "the table name", tt.opts...)
+			cmd.SetArgs([]string{"--table", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, table)
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package cmdutil
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NumericStringFlag defines a new flag that accepts a fixed-width numeric identifier
+// such as a zip code or PIN, validating it is exactly the given number of ASCII digits
+// and storing it as a string so leading zeros are preserved.
+func NumericStringFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue string, digits int, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&numericStringValue{string: p, digits: digits}, name, shorthand, usage)
+}
+
+type numericStringValue struct {
+	string *string
+	digits int
+}
+
+func (v *numericStringValue) Set(value string) error {
+	if len(value) != v.digits || strings.IndexFunc(value, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return fmt.Errorf("must be exactly %d digits", v.digits)
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *numericStringValue) String() string {
+	return *v.string
+}
+
+func (v *numericStringValue) Type() string {
+	return "string"
+}
+
+// NoSurroundingWhitespaceStringFlag defines a new string flag that rejects values with
+// leading or trailing whitespace instead of silently trimming it. For exact-match
+// identifiers an accidental surrounding space is a real bug the user should notice.
+func NoSurroundingWhitespaceStringFlag(cmd *cobra.Command, p *string, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&noSurroundingWhitespaceValue{string: p}, name, shorthand, usage)
+}
+
+type noSurroundingWhitespaceValue struct {
+	string *string
+}
+
+func (v *noSurroundingWhitespaceValue) Set(value string) error {
+	if value != strings.TrimSpace(value) {
+		var positions []string
+		if strings.TrimLeft(value, " \t\r\n") != value {
+			positions = append(positions, "leading whitespace at position 0")
+		}
+		if strings.TrimRight(value, " \t\r\n") != value {
+			positions = append(positions, fmt.Sprintf("trailing whitespace at position %d", len(value)-1))
+		}
+		return fmt.Errorf("value has %s", strings.Join(positions, " and "))
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *noSurroundingWhitespaceValue) String() string {
+	if v.string == nil {
+		return ""
+	}
+	return *v.string
+}
+
+func (v *noSurroundingWhitespaceValue) Type() string {
+	return "string"
+}
+
+// LinesFlag defines a new flag that may be repeated to build up a multiline string,
+// e.g. `--line "first" --line "second"`. The accumulated lines are stored in *p joined
+// by newlines; FlagLines returns the raw slice.
+func LinesFlag(cmd *cobra.Command, p *string, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&linesValue{joined: p}, name, shorthand, usage)
+}
+
+// FlagLines returns the individual lines accumulated by a LinesFlag.
+func FlagLines(f *pflag.Flag) []string {
+	if v, ok := f.Value.(*linesValue); ok {
+		return v.lines
+	}
+	return nil
+}
+
+type linesValue struct {
+	joined *string
+	lines  []string
+}
+
+func (v *linesValue) Set(value string) error {
+	v.lines = append(v.lines, value)
+	*v.joined = strings.Join(v.lines, "\n")
+	return nil
+}
+
+func (v *linesValue) String() string {
+	if v.joined == nil {
+		return ""
+	}
+	return *v.joined
+}
+
+func (v *linesValue) Type() string {
+	return "stringArray"
+}
+
+// RegexpCompileFlag defines a new flag whose value is itself a regular expression,
+// compiled at parse time and stored as a *regexp.Regexp. This is the inverse of
+// StringRegexpFlag, which validates input against a fixed pattern.
+func RegexpCompileFlag(cmd *cobra.Command, p **regexp.Regexp, name, shorthand string, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&decodedValue[*regexp.Regexp]{value: p, typeName: "regexp", decode: regexp.Compile}, name, shorthand, usage)
+}
+
+// StringRegexpFlag defines a new string flag whose value must match the fixed pattern re.
+func StringRegexpFlag(cmd *cobra.Command, p *string, name, shorthand string, re *regexp.Regexp, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&regexpStringValue{string: p, re: re}, name, shorthand, usage)
+}
+
+// StringRegexpNamedFlag is like StringRegexpFlag for patterns using named capture
+// groups (?P<name>...): on a successful match the groups map is populated with the
+// captured submatches keyed by group name, and the full match is stored in *p.
+func StringRegexpNamedFlag(cmd *cobra.Command, p *string, groups *map[string]string, name, shorthand string, re *regexp.Regexp, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&regexpStringValue{string: p, groups: groups, re: re}, name, shorthand, usage)
+}
+
+type regexpStringValue struct {
+	string *string
+	groups *map[string]string
+	re     *regexp.Regexp
+}
+
+func (v *regexpStringValue) Set(value string) error {
+	match := v.re.FindStringSubmatch(value)
+	if match == nil || match[0] != value {
+		return fmt.Errorf("value does not match %s", v.re)
+	}
+	if v.groups != nil {
+		groups := map[string]string{}
+		for i, name := range v.re.SubexpNames() {
+			if name != "" {
+				groups[name] = match[i]
+			}
+		}
+		*v.groups = groups
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *regexpStringValue) String() string {
+	if v.string == nil {
+		return ""
+	}
+	return *v.string
+}
+
+func (v *regexpStringValue) Type() string {
+	return "string"
+}
+
+// StringLengthOpts customizes how StringLengthFlag measures length.
+type StringLengthOpts struct {
+	// Graphemes counts grapheme clusters (user-visible characters) instead of runes.
+	// This matters for emoji and combining characters, where rune count overestimates
+	// the visible length.
+	Graphemes bool
+}
+
+// StringLengthFlag defines a new string flag whose length must fall within
+// [minLen, maxLen]. Length is measured in runes unless opts.Graphemes is set. A
+// maxLen of 0 means no upper bound.
+func StringLengthFlag(cmd *cobra.Command, p *string, name, shorthand string, minLen, maxLen int, opts StringLengthOpts, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&stringLengthValue{string: p, min: minLen, max: maxLen, opts: opts}, name, shorthand, usage)
+}
+
+type stringLengthValue struct {
+	string   *string
+	min, max int
+	opts     StringLengthOpts
+}
+
+func (v *stringLengthValue) Set(value string) error {
+	length := utf8.RuneCountInString(value)
+	if v.opts.Graphemes {
+		length = uniseg.GraphemeClusterCount(value)
+	}
+	if length < v.min || (v.max > 0 && length > v.max) {
+		return fmt.Errorf("value must be between %d and %d characters (got %d)", v.min, v.max, length)
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *stringLengthValue) String() string {
+	if v.string == nil {
+		return ""
+	}
+	return *v.string
+}
+
+func (v *stringLengthValue) Type() string {
+	return "string"
+}
+
+// WordCountStringFlag defines a new string flag whose word count must fall within
+// [minWords, maxWords], e.g. a summary that must be 3 to 20 words. Words are separated
+// by whitespace runs. A maxWords of 0 means no upper bound.
+func WordCountStringFlag(cmd *cobra.Command, p *string, name, shorthand string, minWords, maxWords int, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&wordCountValue{string: p, min: minWords, max: maxWords}, name, shorthand, usage)
+}
+
+type wordCountValue struct {
+	string   *string
+	min, max int
+}
+
+func (v *wordCountValue) Set(value string) error {
+	count := len(strings.Fields(value))
+	if count < v.min || (v.max > 0 && count > v.max) {
+		return fmt.Errorf("value must be between %d and %d words (got %d)", v.min, v.max, count)
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *wordCountValue) String() string {
+	if v.string == nil {
+		return ""
+	}
+	return *v.string
+}
+
+func (v *wordCountValue) Type() string {
+	return "string"
+}
+
+// NonEmptyStringFlag defines a new string flag that trims surrounding whitespace and
+// rejects values that are empty after trimming. This combines required-ness with
+// trimming in one flag type, avoiding a separate emptiness check in RunE.
+func NonEmptyStringFlag(cmd *cobra.Command, p *string, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&nonEmptyStringValue{string: p}, name, shorthand, usage)
+}
+
+type nonEmptyStringValue struct {
+	string *string
+}
+
+func (v *nonEmptyStringValue) Set(value string) error {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return fmt.Errorf("value cannot be empty")
+	}
+	*v.string = trimmed
+	return nil
+}
+
+func (v *nonEmptyStringValue) String() string {
+	if v.string == nil {
+		return ""
+	}
+	return *v.string
+}
+
+func (v *nonEmptyStringValue) Type() string {
+	return "string"
+}
+
+// NFCStringFlag defines a new string flag that applies Unicode NFC normalization when
+// storing the value. The same visible string can arrive in different normal forms
+// (e.g. "é" as one codepoint or as "e" plus a combining accent), which breaks dedup
+// and equality when names are compared across systems. Normalization is opt-in via
+// this constructor because it alters the bytes the user supplied.
+func NFCStringFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&nfcStringValue{string: p}, name, shorthand, usage)
+}
+
+type nfcStringValue struct {
+	string *string
+}
+
+func (v *nfcStringValue) Set(value string) error {
+	*v.string = norm.NFC.String(value)
+	return nil
+}
+
+func (v *nfcStringValue) String() string {
+	return *v.string
+}
+
+func (v *nfcStringValue) Type() string {
+	return "string"
+}
+
+// UTF8StringFlag defines a new string flag that rejects values containing invalid UTF-8
+// sequences, reporting the byte offset of the first invalid byte. This catches encoding
+// problems early when values are piped in from files or other tools.
+func UTF8StringFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&utf8StringValue{string: p}, name, shorthand, usage)
+}
+
+type utf8StringValue struct {
+	string *string
+}
+
+func (v *utf8StringValue) Set(value string) error {
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		if r == utf8.RuneError && size == 1 {
+			return fmt.Errorf("invalid UTF-8 sequence at byte %d", i)
+		}
+		i += size
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *utf8StringValue) String() string {
+	return *v.string
+}
+
+func (v *utf8StringValue) Type() string {
+	return "string"
+}
+
+// envVarNameRE is the POSIX environment variable name rule: letters, digits, and
+// underscores, not starting with a digit.
+var envVarNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvVarNameFlag defines a new string flag that accepts an environment variable name,
+// validating it against the POSIX name rule so generated shell exports are always
+// well-formed. The value is stored unchanged since names are case-sensitive.
+func EnvVarNameFlag(cmd *cobra.Command, p *string, name, shorthand, defaultValue, usage string) *pflag.Flag {
+	*p = defaultValue
+	return cmd.Flags().VarPF(&envVarNameValue{string: p}, name, shorthand, usage)
+}
+
+type envVarNameValue struct {
+	string *string
+}
+
+func (v *envVarNameValue) Set(value string) error {
+	if !envVarNameRE.MatchString(value) {
+		return fmt.Errorf("invalid environment variable name %q: names may contain only letters, digits, and underscores, and cannot start with a digit", value)
+	}
+	*v.string = value
+	return nil
+}
+
+func (v *envVarNameValue) String() string {
+	return *v.string
+}
+
+func (v *envVarNameValue) Type() string {
+	return "name"
+}
@@ -0,0 +1,468 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"regexp"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumericStringFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsError string
+	}{
+		{
+			name:  "leading zeros preserved",
+			value: "01234",
+		},
+		{
+			name:       "too short",
+			value:      "123",
+			wantsError: `invalid argument "123" for "--zip" flag: must be exactly 5 digits`,
+		},
+		{
+			name:       "non-digit",
+			value:      "12a45",
+			wantsError: `invalid argument "12a45" for "--zip" flag: must be exactly 5 digits`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var zip string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			NumericStringFlag(cmd, &zip, "zip", "", "", 5, "the zip code")
+			cmd.SetArgs([]string{"--zip", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, zip)
+		})
+	}
+}
+
+func TestNoSurroundingWhitespaceStringFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsError string
+	}{
+		{
+			name:  "clean value",
+			value: "deploy-key",
+		},
+		{
+			name:  "interior whitespace allowed",
+			value: "deploy key",
+		},
+		{
+			name:       "leading whitespace",
+			value:      " deploy-key",
+			wantsError: `invalid argument " deploy-key" for "--id" flag: value has leading whitespace at position 0`,
+		},
+		{
+			name:       "trailing whitespace",
+			value:      "deploy-key\t",
+			wantsError: "invalid argument \"deploy-key\\t\" for \"--id\" flag: value has trailing whitespace at position 10",
+		},
+		{
+			name:       "both",
+			value:      " deploy-key ",
+			wantsError: `invalid argument " deploy-key " for "--id" flag: value has leading whitespace at position 0 and trailing whitespace at position 11`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			NoSurroundingWhitespaceStringFlag(cmd, &id, "id", "", "the identifier")
+			cmd.SetArgs([]string{"--id", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, id)
+		})
+	}
+}
+
+func TestLinesFlag(t *testing.T) {
+	var body string
+	cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+	f := LinesFlag(cmd, &body, "line", "", "a line of the body")
+	cmd.SetArgs([]string{"--line", "first", "--line", "second", "--line", ""})
+	cmd.SetOut(ioutil.Discard)
+	cmd.SetErr(ioutil.Discard)
+	_, err := cmd.ExecuteC()
+	assert.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", body)
+	assert.Equal(t, []string{"first", "second", ""}, FlagLines(f))
+}
+
+func TestStringRegexpNamedFlag(t *testing.T) {
+	re := regexp.MustCompile(`(?P<owner>[\w-]+)/(?P<repo>[\w-]+)`)
+	tests := []struct {
+		name        string
+		value       string
+		wantsGroups map[string]string
+		wantsError  string
+	}{
+		{
+			name:        "populates named groups",
+			value:       "cli/cli",
+			wantsGroups: map[string]string{"owner": "cli", "repo": "cli"},
+		},
+		{
+			name:       "no match",
+			value:      "just-a-name",
+			wantsError: `invalid argument "just-a-name" for "--repo" flag: value does not match (?P<owner>[\w-]+)/(?P<repo>[\w-]+)`,
+		},
+		{
+			name:       "partial match rejected",
+			value:      "cli/cli/extra",
+			wantsError: `invalid argument "cli/cli/extra" for "--repo" flag: value does not match (?P<owner>[\w-]+)/(?P<repo>[\w-]+)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var repo string
+			var groups map[string]string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringRegexpNamedFlag(cmd, &repo, &groups, "repo", "", re, "the repository")
+			cmd.SetArgs([]string{"--repo", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, repo)
+			assert.Equal(t, tt.wantsGroups, groups)
+		})
+	}
+}
+
+func TestStringLengthFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       StringLengthOpts
+		value      string
+		wantsError string
+	}{
+		{
+			name:  "within bounds",
+			value: "gopher",
+		},
+		{
+			name:       "too long in runes",
+			value:      "0123456789X",
+			wantsError: `invalid argument "0123456789X" for "--display-name" flag: value must be between 1 and 10 characters (got 11)`,
+		},
+		{
+			name:  "emoji counted per grapheme",
+			opts:  StringLengthOpts{Graphemes: true},
+			value: "👩‍👩‍👦 family",
+		},
+		{
+			name:       "empty",
+			value:      "",
+			wantsError: `invalid argument "" for "--display-name" flag: value must be between 1 and 10 characters (got 0)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var displayName string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			StringLengthFlag(cmd, &displayName, "display-name", "", 1, 10, tt.opts, "the display name")
+			cmd.SetArgs([]string{"--display-name", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, displayName)
+		})
+	}
+}
+
+func TestNonEmptyStringFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsValue string
+		wantsError string
+	}{
+		{
+			name:       "plain value",
+			value:      "hello",
+			wantsValue: "hello",
+		},
+		{
+			name:       "trims whitespace",
+			value:      "  hello  ",
+			wantsValue: "hello",
+		},
+		{
+			name:       "whitespace only",
+			value:      "   ",
+			wantsError: `invalid argument "   " for "--title" flag: value cannot be empty`,
+		},
+		{
+			name:       "empty",
+			value:      "",
+			wantsError: `invalid argument "" for "--title" flag: value cannot be empty`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var title string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			NonEmptyStringFlag(cmd, &title, "title", "", "the title")
+			cmd.SetArgs([]string{"--title", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, title)
+		})
+	}
+}
+
+func TestUTF8StringFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantsValue string
+		wantsError string
+	}{
+		{
+			name:       "valid ASCII",
+			args:       []string{"--body", "hello"},
+			wantsValue: "hello",
+		},
+		{
+			name:       "valid multibyte",
+			args:       []string{"--body", "héllo 👋"},
+			wantsValue: "héllo 👋",
+		},
+		{
+			name:       "invalid sequence",
+			args:       []string{"--body", "ok\xffnope"},
+			wantsError: "invalid argument \"ok\\xffnope\" for \"--body\" flag: invalid UTF-8 sequence at byte 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			UTF8StringFlag(cmd, &body, "body", "", "", "the body text")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, body)
+		})
+	}
+}
+
+func TestRegexpCompileFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		matches    string
+		wantsError string
+	}{
+		{
+			name:    "compiles and stores",
+			value:   `^v\d+\.\d+$`,
+			matches: "v1.25",
+		},
+		{
+			name:       "compile error",
+			value:      "[unterminated",
+			wantsError: "invalid argument \"[unterminated\" for \"--pattern\" flag: error parsing regexp: missing closing ]: `[unterminated`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pattern *regexp.Regexp
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			RegexpCompileFlag(cmd, &pattern, "pattern", "", "the pattern")
+			cmd.SetArgs([]string{"--pattern", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, pattern.MatchString(tt.matches))
+		})
+	}
+}
+
+func TestWordCountStringFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsError string
+	}{
+		{
+			name:  "exactly min",
+			value: "fixes the bug",
+		},
+		{
+			name:  "whitespace runs collapse",
+			value: "fixes   the\t bug   properly",
+		},
+		{
+			name:       "empty",
+			value:      "",
+			wantsError: `invalid argument "" for "--summary" flag: value must be between 3 and 5 words (got 0)`,
+		},
+		{
+			name:       "under min",
+			value:      "too short",
+			wantsError: `invalid argument "too short" for "--summary" flag: value must be between 3 and 5 words (got 2)`,
+		},
+		{
+			name:       "over max",
+			value:      "this summary has far too many words in it",
+			wantsError: `invalid argument "this summary has far too many words in it" for "--summary" flag: value must be between 3 and 5 words (got 9)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var summary string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			WordCountStringFlag(cmd, &summary, "summary", "", 3, 5, "the summary")
+			cmd.SetArgs([]string{"--summary", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, summary)
+		})
+	}
+}
+
+func TestNFCStringFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantsValue string
+	}{
+		{
+			name:       "already NFC",
+			value:      "café",
+			wantsValue: "café",
+		},
+		{
+			name:       "decomposed form normalized",
+			value:      "café",
+			wantsValue: "café",
+		},
+		{
+			name:       "plain ASCII untouched",
+			value:      "cafe",
+			wantsValue: "cafe",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var name string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			NFCStringFlag(cmd, &name, "name", "", "", "the name")
+			cmd.SetArgs([]string{"--name", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantsValue, name)
+		})
+	}
+}
+
+func TestEnvVarNameFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wants   string
+		wantErr string
+	}{
+		{
+			name:  "upper snake case",
+			args:  []string{"--export-as", "MY_VAR"},
+			wants: "MY_VAR",
+		},
+		{
+			name:  "case preserved",
+			args:  []string{"--export-as", "myVar_2"},
+			wants: "myVar_2",
+		},
+		{
+			name:  "leading underscore",
+			args:  []string{"--export-as", "_TOKEN"},
+			wants: "_TOKEN",
+		},
+		{
+			name:    "leading digit",
+			args:    []string{"--export-as", "2FAST"},
+			wantErr: `invalid argument "2FAST" for "--export-as" flag: invalid environment variable name "2FAST": names may contain only letters, digits, and underscores, and cannot start with a digit`,
+		},
+		{
+			name:    "illegal character",
+			args:    []string{"--export-as", "MY-VAR"},
+			wantErr: `invalid argument "MY-VAR" for "--export-as" flag: invalid environment variable name "MY-VAR": names may contain only letters, digits, and underscores, and cannot start with a digit`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exportAs string
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			EnvVarNameFlag(cmd, &exportAs, "export-as", "", "", "name to export the value under")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wants, exportAs)
+		})
+	}
+}
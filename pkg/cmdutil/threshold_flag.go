@@ -0,0 +1,67 @@
+package cmdutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Threshold expresses an allowed change relative to a baseline, e.g. "+5%" permits the
+// actual value to exceed the baseline by up to 5%, and "-10" permits it to fall up to
+// 10 below. Sign is +1 or -1 for the direction of tolerated change, and Value is the
+// magnitude, interpreted as a percentage of the baseline when IsPercent is set.
+type Threshold struct {
+	Sign      int
+	IsPercent bool
+	Value     float64
+}
+
+// Passes reports whether actual stays within the tolerated change from baseline.
+// Change in the direction opposite the threshold's sign always passes.
+func (t Threshold) Passes(baseline, actual float64) bool {
+	limit := t.Value
+	if t.IsPercent {
+		baselineMagnitude := baseline
+		if baselineMagnitude < 0 {
+			baselineMagnitude = -baselineMagnitude
+		}
+		limit = baselineMagnitude * t.Value / 100
+	}
+	delta := actual - baseline
+	if t.Sign < 0 {
+		delta = -delta
+	}
+	return delta <= limit
+}
+
+// ThresholdFlag defines a new flag that accepts a signed tolerance such as "+5%" or
+// "-10", for regression gates comparing a measured value against a baseline.
+func ThresholdFlag(cmd *cobra.Command, p *Threshold, name, shorthand, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&decodedValue[Threshold]{value: p, typeName: "threshold", decode: parseThreshold}, name, shorthand, usage)
+}
+
+func parseThreshold(value string) (Threshold, error) {
+	if value == "" || (value[0] != '+' && value[0] != '-') {
+		return Threshold{}, fmt.Errorf("expected a signed number like \"+5%%\" or \"-10\", got %q", value)
+	}
+	sign := 1
+	if value[0] == '-' {
+		sign = -1
+	}
+	rest := value[1:]
+	isPercent := strings.HasSuffix(rest, "%")
+	if isPercent {
+		rest = strings.TrimSuffix(rest, "%")
+	}
+	n, err := strconv.ParseFloat(rest, 64)
+	if err != nil {
+		return Threshold{}, fmt.Errorf("expected a signed number like \"+5%%\" or \"-10\", got %q", value)
+	}
+	if n < 0 {
+		return Threshold{}, fmt.Errorf("threshold magnitude must not be negative")
+	}
+	return Threshold{Sign: sign, IsPercent: isPercent, Value: n}, nil
+}
@@ -0,0 +1,134 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wants   Threshold
+		wantErr string
+	}{
+		{
+			name:  "positive percentage",
+			args:  []string{"--threshold", "+5%"},
+			wants: Threshold{Sign: 1, IsPercent: true, Value: 5},
+		},
+		{
+			name:  "negative absolute",
+			args:  []string{"--threshold", "-10"},
+			wants: Threshold{Sign: -1, Value: 10},
+		},
+		{
+			name:  "decimal percentage",
+			args:  []string{"--threshold", "-2.5%"},
+			wants: Threshold{Sign: -1, IsPercent: true, Value: 2.5},
+		},
+		{
+			name:    "missing sign",
+			args:    []string{"--threshold", "5%"},
+			wantErr: `invalid argument "5%" for "--threshold" flag: expected a signed number like "+5%" or "-10", got "5%"`,
+		},
+		{
+			name:    "malformed number",
+			args:    []string{"--threshold", "+few%"},
+			wantErr: `invalid argument "+few%" for "--threshold" flag: expected a signed number like "+5%" or "-10", got "+few%"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var threshold Threshold
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			ThresholdFlag(cmd, &threshold, "threshold", "", "allowed change from the baseline")
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wants, threshold)
+		})
+	}
+}
+
+func TestThresholdPasses(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold Threshold
+		baseline  float64
+		actual    float64
+		wants     bool
+	}{
+		{
+			name:      "increase within absolute tolerance",
+			threshold: Threshold{Sign: 1, Value: 10},
+			baseline:  100,
+			actual:    108,
+			wants:     true,
+		},
+		{
+			name:      "increase beyond absolute tolerance",
+			threshold: Threshold{Sign: 1, Value: 10},
+			baseline:  100,
+			actual:    111,
+			wants:     false,
+		},
+		{
+			name:      "decrease always passes an increase threshold",
+			threshold: Threshold{Sign: 1, Value: 10},
+			baseline:  100,
+			actual:    50,
+			wants:     true,
+		},
+		{
+			name:      "increase within percentage tolerance",
+			threshold: Threshold{Sign: 1, IsPercent: true, Value: 5},
+			baseline:  200,
+			actual:    209,
+			wants:     true,
+		},
+		{
+			name:      "increase beyond percentage tolerance",
+			threshold: Threshold{Sign: 1, IsPercent: true, Value: 5},
+			baseline:  200,
+			actual:    211,
+			wants:     false,
+		},
+		{
+			name:      "decrease within absolute tolerance",
+			threshold: Threshold{Sign: -1, Value: 10},
+			baseline:  100,
+			actual:    92,
+			wants:     true,
+		},
+		{
+			name:      "decrease beyond percentage tolerance",
+			threshold: Threshold{Sign: -1, IsPercent: true, Value: 5},
+			baseline:  200,
+			actual:    185,
+			wants:     false,
+		},
+		{
+			name:      "increase always passes a decrease threshold",
+			threshold: Threshold{Sign: -1, Value: 10},
+			baseline:  100,
+			actual:    150,
+			wants:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wants, tt.threshold.Passes(tt.baseline, tt.actual))
+		})
+	}
+}
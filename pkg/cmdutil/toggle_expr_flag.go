@@ -0,0 +1,178 @@
+package cmdutil
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ToggleExpr is a compiled boolean expression over feature toggle names, e.g.
+// "prod && !canary", supporting &&, ||, ! and parentheses.
+type ToggleExpr struct {
+	source string
+	root   toggleNode
+}
+
+// Eval evaluates the expression against the given set of enabled toggles.
+func (e *ToggleExpr) Eval(enabled map[string]bool) bool {
+	if e == nil || e.root == nil {
+		return false
+	}
+	return e.root.eval(enabled)
+}
+
+func (e *ToggleExpr) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.source
+}
+
+// ToggleExprFlag defines a new flag accepting a boolean expression of feature toggles.
+// The expression is validated at parse time: it must be syntactically well-formed and
+// reference only names in knownToggles. The compiled predicate is stored in *p.
+func ToggleExprFlag(cmd *cobra.Command, p *ToggleExpr, name, shorthand string, knownToggles []string, usage string) *pflag.Flag {
+	return cmd.Flags().VarPF(&toggleExprValue{expr: p, known: knownToggles}, name, shorthand, usage)
+}
+
+type toggleExprValue struct {
+	expr  *ToggleExpr
+	known []string
+}
+
+func (v *toggleExprValue) Set(value string) error {
+	parser := &toggleParser{input: value, known: v.known}
+	root, err := parser.parseOr()
+	if err != nil {
+		return err
+	}
+	parser.skipSpaces()
+	if parser.pos != len(parser.input) {
+		return fmt.Errorf("unexpected %q at offset %d", parser.input[parser.pos:], parser.pos)
+	}
+	*v.expr = ToggleExpr{source: value, root: root}
+	return nil
+}
+
+func (v *toggleExprValue) String() string {
+	return v.expr.String()
+}
+
+func (v *toggleExprValue) Type() string {
+	return "expression"
+}
+
+type toggleNode interface {
+	eval(enabled map[string]bool) bool
+}
+
+type toggleLeaf string
+
+func (n toggleLeaf) eval(enabled map[string]bool) bool { return enabled[string(n)] }
+
+type toggleNot struct{ operand toggleNode }
+
+func (n toggleNot) eval(enabled map[string]bool) bool { return !n.operand.eval(enabled) }
+
+type toggleBinary struct {
+	or          bool
+	left, right toggleNode
+}
+
+func (n toggleBinary) eval(enabled map[string]bool) bool {
+	if n.or {
+		return n.left.eval(enabled) || n.right.eval(enabled)
+	}
+	return n.left.eval(enabled) && n.right.eval(enabled)
+}
+
+type toggleParser struct {
+	input string
+	pos   int
+	known []string
+}
+
+func (p *toggleParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *toggleParser) accept(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *toggleParser) parseOr() (toggleNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = toggleBinary{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *toggleParser) parseAnd() (toggleNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = toggleBinary{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *toggleParser) parseUnary() (toggleNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return toggleNot{operand: operand}, nil
+	}
+	if p.accept("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing closing parenthesis at offset %d", p.pos)
+		}
+		return expr, nil
+	}
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) {
+		r := rune(p.input[p.pos])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			break
+		}
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, fmt.Errorf("expected a toggle name at offset %d", start)
+	}
+	name := p.input[start:p.pos]
+	if !isEnumOption(name, p.known) {
+		return nil, fmt.Errorf("unknown toggle %q: valid toggles are %s", name, formatValuesForUsageDocs(p.known))
+	}
+	return toggleLeaf(name), nil
+}
@@ -0,0 +1,72 @@
+package cmdutil
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToggleExprFlag(t *testing.T) {
+	knownToggles := []string{"prod", "canary", "beta"}
+	tests := []struct {
+		name       string
+		value      string
+		enabled    map[string]bool
+		wantsEval  bool
+		wantsError string
+	}{
+		{
+			name:      "conjunction with negation",
+			value:     "prod && !canary",
+			enabled:   map[string]bool{"prod": true},
+			wantsEval: true,
+		},
+		{
+			name:      "parenthesized disjunction",
+			value:     "(beta || canary) && prod",
+			enabled:   map[string]bool{"prod": true, "beta": true},
+			wantsEval: true,
+		},
+		{
+			name:      "false evaluation",
+			value:     "prod && canary",
+			enabled:   map[string]bool{"prod": true},
+			wantsEval: false,
+		},
+		{
+			name:       "unknown toggle",
+			value:      "prod && staging",
+			wantsError: `invalid argument "prod && staging" for "--when" flag: unknown toggle "staging": valid toggles are {prod|canary|beta}`,
+		},
+		{
+			name:       "syntax error",
+			value:      "prod &&",
+			wantsError: `invalid argument "prod &&" for "--when" flag: expected a toggle name at offset 7`,
+		},
+		{
+			name:       "unbalanced parenthesis",
+			value:      "(prod || beta",
+			wantsError: `invalid argument "(prod || beta" for "--when" flag: missing closing parenthesis at offset 13`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var when ToggleExpr
+			cmd := &cobra.Command{Run: func(*cobra.Command, []string) {}}
+			ToggleExprFlag(cmd, &when, "when", "", knownToggles, "the condition")
+			cmd.SetArgs([]string{"--when", tt.value})
+			cmd.SetOut(ioutil.Discard)
+			cmd.SetErr(ioutil.Discard)
+			_, err := cmd.ExecuteC()
+			if tt.wantsError != "" {
+				assert.EqualError(t, err, tt.wantsError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, when.String())
+			assert.Equal(t, tt.wantsEval, when.Eval(tt.enabled))
+		})
+	}
+}